@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestListAction(t *testing.T) {
@@ -62,7 +64,7 @@ func TestListAction(t *testing.T) {
 
 			var out bytes.Buffer
 
-			err := listAction(&out, url, tc.isActive)
+			err := listAction(&out, newHTTPAPI(url), printer{}, tc.isActive)
 			if tc.expError != nil {
 				if err == nil {
 					t.Fatalf("Expected error %q, no error", tc.expError)
@@ -132,7 +134,7 @@ Completed:    No
 				cleanup()
 			}
 			var out bytes.Buffer
-			err := viewAction(&out, url, tc.id)
+			err := viewAction(&out, newHTTPAPI(url), printer{}, tc.id)
 			if tc.expError != nil {
 				if err == nil {
 					t.Error("Exp to have error got nil\n")
@@ -191,7 +193,7 @@ func TestAddAction(t *testing.T) {
 
 	var out bytes.Buffer
 
-	err := addAction(&out, url, args)
+	err := addAction(&out, newHTTPAPI(url), printer{}, args, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -201,6 +203,43 @@ func TestAddAction(t *testing.T) {
 	}
 }
 
+func TestAddDue(t *testing.T) {
+	server := newFakeServer()
+
+	var out bytes.Buffer
+	if err := addAction(&out, server, printer{}, []string{"Due", "task"}, "2024-03-01"); err != nil {
+		t.Fatal(err)
+	}
+	expOut := "Added task \"Due task\" to the list, due Mar/01 @23:59.\n"
+	if out.String() != expOut {
+		t.Errorf("Expect %q, got %q", expOut, out.String())
+	}
+	expDue := time.Date(2024, 3, 1, 23, 59, 59, 0, time.Local)
+	if !server.items[3].Due.Equal(expDue) {
+		t.Errorf("Expect due %s, got %s", expDue, server.items[3].Due)
+	}
+
+	out.Reset()
+	if err := listAction(&out, server, printer{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "4  Due task  due Mar/01 @23:59") {
+		t.Errorf("Expect the due date listed, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := viewAction(&out, server, printer{}, "4"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Due:          Mar/01 @23:59") {
+		t.Errorf("Expect the due date shown, got %q", out.String())
+	}
+
+	if err := addAction(&out, server, printer{}, []string{"Bad"}, "someday"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expect error %q, got %v", ErrInvalid, err)
+	}
+}
+
 func TestCompleteAction(t *testing.T) {
 	expURLPath := "/todo/1"
 	expMethod := http.MethodPatch
@@ -225,7 +264,7 @@ func TestCompleteAction(t *testing.T) {
 	defer cleanup()
 
 	var out bytes.Buffer
-	err := completeAction(&out, url, arg)
+	err := completeAction(&out, newHTTPAPI(url), printer{}, arg)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -255,7 +294,7 @@ func TestDeleteAction(t *testing.T) {
 	defer cleanup()
 
 	var out bytes.Buffer
-	err := deleteAction(&out, url, arg)
+	err := deleteAction(&out, newHTTPAPI(url), printer{}, arg)
 	if err != nil {
 		t.Fatal(err)
 	}
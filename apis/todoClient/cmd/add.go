@@ -26,6 +26,8 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
+	"todo"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -38,25 +40,63 @@ var addCmd = &cobra.Command{
 	SilenceUsage: true,
 	Args:         cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiRoot := viper.GetString("api-root")
-		return addAction(os.Stdout, apiRoot, args)
+		p, err := newPrinter(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		due, err := cmd.Flags().GetString("due")
+		if err != nil {
+			return err
+		}
+		return addAction(os.Stdout, api, p, args, due)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().String("due", "", "Due date of the task, as YYYY-MM-DD for the end of that day or an RFC 3339 time")
 }
 
-func addAction(out io.Writer, endpoint string, args []string) error {
+// addAction adds the task, due at due unless blank
+func addAction(out io.Writer, api todoAPI, p printer, args []string, due string) error {
+	var dueAt time.Time
+	if due != "" {
+		d, err := todo.ParseDue(due, time.Local)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		dueAt = d
+	}
+
 	task := strings.Join(args, " ")
-	if err := addItem(endpoint, task); err != nil {
+	if err := api.addItem(task, dueAt); err != nil {
 		return err
 	}
+	if p.table() {
+		return printAdd(out, task, dueAt)
+	}
 
-	return printAdd(out, task)
+	// The server appends new items to the list
+	items, err := api.getAll()
+	if err != nil {
+		return err
+	}
+	id := len(items)
+	return p.printItem(out, newOutputItem(id, items[id-1]))
 }
 
-func printAdd(out io.Writer, task string) error {
+func printAdd(out io.Writer, task string, due time.Time) error {
+	if !due.IsZero() {
+		_, err := fmt.Fprintf(out, "Added task %q to the list, due %s.\n", task, due.Format(timeFormat))
+		return err
+	}
 	_, err := fmt.Fprintf(out, "Added task %q to the list.\n", task)
 	return err
 }
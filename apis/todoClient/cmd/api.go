@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"apis/todoClient/todoapi"
+
+	"github.com/spf13/viper"
+)
+
+// todoAPI is implemented by each transport todoClient can use to reach the server
+type todoAPI interface {
+	getAll() ([]item, error)
+	getOne(id int) (item, error)
+	// addItem appends a task, due when due isn't zero
+	addItem(task string, due time.Time) error
+	completeItem(id int) error
+	deleteItem(id int) error
+}
+
+// newAPI returns the API for the configured transport, with offline support,
+// and a function releasing it
+func newAPI() (*offlineAPI, func(), error) {
+	return openAPI(viper.GetDuration("timeout"), viper.GetInt("retries"))
+}
+
+// openAPI is newAPI with the given timeout and number of retries instead of
+// the configured ones
+func openAPI(timeout time.Duration, retries int) (*offlineAPI, func(), error) {
+	cacheDir, err := cacheDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch transport := viper.GetString("transport"); transport {
+	case "", "http":
+		client, err := newRESTClient(cacheDir, timeout, retries)
+		if err != nil {
+			return nil, nil, err
+		}
+		a := httpAPI{client: client}
+		return newOfflineAPI(a, cacheDir, client.BaseURL, os.Stderr), func() {}, nil
+	case "grpc":
+		addr := viper.GetString("grpc-addr")
+		c, err := activeContext()
+		if err != nil {
+			return nil, nil, err
+		}
+		a, err := newGRPCAPI(addr, timeout, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		return newOfflineAPI(a, cacheDir, "grpc-"+addr, os.Stderr), func() { a.close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown transport %q", ErrInvalid, transport)
+	}
+}
+
+// newRESTClient returns a client for the configured REST API, applying the
+// active context, the retries and the circuit breaker
+func newRESTClient(cacheDir string, timeout time.Duration, retries int) (*todoapi.Client, error) {
+	c, err := activeContext()
+	if err != nil {
+		return nil, err
+	}
+	client := todoapi.NewClient(apiRoot(viper.GetViper(), c))
+	client.Retry = todoapi.DefaultRetryPolicy(retries)
+
+	var tlsConfig *tls.Config
+	if c != nil {
+		client.Token = c.Token
+		if tlsConfig, err = c.TLS.config(); err != nil {
+			return nil, err
+		}
+	}
+	client.HTTPClient = newHTTPClient(timeout, tlsConfig)
+	client.Breaker = newCircuitBreaker(cacheDir, client.BaseURL)
+	return client, nil
+}
+
+// apiRoot returns the API root set in v, with the one of the context c as
+// default, so --api-root, TODO_API_ROOT and the config file win over it
+func apiRoot(v *viper.Viper, c *clientContext) string {
+	if c != nil {
+		v.SetDefault("api-root", c.APIRoot)
+	}
+	return v.GetString("api-root")
+}
+
+// cacheDir returns the directory holding the offline cache and journal
+func cacheDir() (string, error) {
+	if dir := viper.GetString("cache-dir"); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todoClient"), nil
+}
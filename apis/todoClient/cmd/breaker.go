@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	breakerThreshold = 5
+	breakerCooldown  = 30 * time.Second
+)
+
+type breakerState struct {
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// circuitBreaker is a todoapi.Breaker stopping the requests to a server after
+// repeated failures. Its state is kept in a file so it carries over between invocations
+type circuitBreaker struct {
+	mu        sync.Mutex
+	file      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// newCircuitBreaker stores the state of the server identified by name in dir
+func newCircuitBreaker(dir, name string) *circuitBreaker {
+	return &circuitBreaker{
+		file:      filepath.Join(dir, unsafeChars.ReplaceAllString(name, "_")+".breaker.json"),
+		threshold: breakerThreshold,
+		cooldown:  breakerCooldown,
+		now:       time.Now,
+	}
+}
+
+func (b *circuitBreaker) state() breakerState {
+	var s breakerState
+	// A missing or corrupt state file means the circuit is closed
+	_ = readJSON(b.file, &s)
+	return s
+}
+
+// Allow returns an error while the circuit is open. Once the cooldown expires a
+// request is let through to probe the server
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.state()
+	if s.Failures < b.threshold {
+		return nil
+	}
+	if wait := s.OpenedAt.Add(b.cooldown).Sub(b.now()); wait > 0 {
+		return fmt.Errorf("%w: circuit open after %d failures, retrying in %s",
+			ErrConnection, s.Failures, wait.Round(time.Second))
+	}
+	return nil
+}
+
+// Record updates the state with the outcome of a request
+func (b *circuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.state()
+	if !failed {
+		if s.Failures > 0 {
+			_ = writeJSON(b.file, breakerState{})
+		}
+		return
+	}
+
+	s.Failures++
+	if s.Failures >= b.threshold {
+		// Also restarts the cooldown when a half open probe fails
+		s.OpenedAt = b.now()
+	}
+	_ = writeJSON(b.file, s)
+}
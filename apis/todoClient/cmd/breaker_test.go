@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"apis/todoClient/todoapi"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	url, cleanup := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer cleanup()
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	newAPI := func() httpAPI {
+		b := newCircuitBreaker(dir, url)
+		b.now = func() time.Time { return now }
+		c := todoapi.NewClient(url)
+		c.Breaker = b
+		return httpAPI{client: c}
+	}
+
+	for i := 0; i < breakerThreshold; i++ {
+		if _, err := newAPI().getAll(); !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("Expect error %q, got %q", ErrInvalidResponse, err)
+		}
+	}
+
+	// The state is kept across invocations
+	if _, err := newAPI().getAll(); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+	if calls != breakerThreshold {
+		t.Errorf("Expect %d calls, got %d", breakerThreshold, calls)
+	}
+
+	// Half open after the cooldown, the failed probe opens it again
+	now = now.Add(breakerCooldown)
+	if _, err := newAPI().getAll(); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expect error %q, got %q", ErrInvalidResponse, err)
+	}
+	if _, err := newAPI().getAll(); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+	if calls != breakerThreshold+1 {
+		t.Errorf("Expect %d calls, got %d", breakerThreshold+1, calls)
+	}
+}
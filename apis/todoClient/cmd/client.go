@@ -1,149 +1,74 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/tls"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
 	"time"
+
+	"apis/todoClient/todoapi"
 )
 
 var (
-	ErrConnection      = errors.New("Connection Error")
-	ErrNotFound        = errors.New("Not Found")
-	ErrInvalidResponse = errors.New("Invalid Server Response")
-	ErrInvalid         = errors.New("Invalid Data")
+	ErrConnection      = todoapi.ErrConnection
+	ErrNotFound        = todoapi.ErrNotFound
+	ErrInvalidResponse = todoapi.ErrInvalidResponse
+	ErrInvalid         = todoapi.ErrInvalid
 	ErrNotNumber       = errors.New("Not a number")
 )
 
-const timeFormat = "Jan/02 @15:04"
+const (
+	timeFormat    = "Jan/02 @15:04"
+	clientTimeout = todoapi.DefaultTimeout
+)
 
-type item struct {
-	Task        string
-	Done        bool
-	CreatedAt   time.Time
-	CompletedAt time.Time
-}
+type item = todoapi.Item
 
-type todoResponse struct {
-	Results      []item `json:"results"`
-	Date         int64  `json:"date"`
-	TotalResults int    `json:"totalResults"`
+// httpAPI talks to the todo REST API with the todoapi client
+type httpAPI struct {
+	client *todoapi.Client
 }
 
-func newClient() *http.Client {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	return client
+func newHTTPAPI(apiRoot string) httpAPI {
+	return httpAPI{client: todoapi.NewClient(apiRoot)}
 }
 
-func getItems(endpoint string) ([]item, error) {
-	client := newClient()
-	res, err := client.Get(endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrConnection, err)
+// newHTTPClient returns an HTTP client with the given timeout, trusting the
+// servers allowed by tlsConfig when set
+func newHTTPClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	if timeout <= 0 {
+		timeout = clientTimeout
 	}
 
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		msg, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("Cannot read body %w", err)
-		}
-
-		err = ErrInvalidResponse
-		if res.StatusCode == http.StatusNotFound {
-			err = ErrNotFound
-		}
-		return nil, fmt.Errorf("%w: %s", err, msg)
-	}
-
-	var resp todoResponse
-
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("%w: fail to decode json response", err)
+	client := &http.Client{
+		Timeout: timeout,
 	}
-	if resp.TotalResults == 0 {
-		return nil, fmt.Errorf("%w: No results found", ErrNotFound)
+	if tlsConfig != nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = tlsConfig
+		client.Transport = t
 	}
-	return resp.Results, nil
-}
 
-func getAll(apiRoot string) ([]item, error) {
-	u := fmt.Sprintf("%s/todo", apiRoot)
-	return getItems(u)
+	return client
 }
 
-func getOne(apiRoot string, id int) (item, error) {
-	endpoint := fmt.Sprintf("%s/todo/%d", apiRoot, id)
-	items, err := getItems(endpoint)
-	if err != nil {
-		return item{}, err
-	}
-	if len(items) != 1 {
-		return item{}, fmt.Errorf("%w: Invalid results", ErrInvalid)
-	}
-	return items[0], nil
+func (a httpAPI) getAll() ([]item, error) {
+	return a.client.List(context.Background())
 }
 
-func addItem(apiRoot, task string) error {
-	// Make a POST request to the server
-	url := fmt.Sprintf("%s/todo", apiRoot)
-	item := struct {
-		Task string `json:"task"`
-	}{
-		Task: task,
-	}
-
-	var body bytes.Buffer
-
-	if err := json.NewEncoder(&body).Encode(&item); err != nil {
-		return err
-	}
-	return sendRequest(url, http.MethodPost, "application/json", http.StatusCreated, &body)
+func (a httpAPI) getOne(id int) (item, error) {
+	return a.client.Get(context.Background(), id)
 }
 
-func completeItem(apiRoot string, id int) error {
-	url := fmt.Sprintf("%s/todo/%d?complete", apiRoot, id)
-	return sendRequest(url, http.MethodPatch, "", http.StatusNoContent, nil)
+func (a httpAPI) addItem(task string, due time.Time) error {
+	return a.client.AddWithDue(context.Background(), task, due)
 }
 
-func deleteItem(apiRoot string, id int) error {
-	url := fmt.Sprintf("%s/todo/%d", apiRoot, id)
-	return sendRequest(url, http.MethodDelete, "", http.StatusNoContent, nil)
+func (a httpAPI) completeItem(id int) error {
+	return a.client.Complete(context.Background(), id)
 }
 
-func sendRequest(url, method, contentType string, expStatus int, body io.Reader) error {
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return err
-	}
-
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-
-	r, err := newClient().Do(req)
-	if err != nil {
-		return err
-	}
-	defer r.Body.Close()
-	if r.StatusCode != expStatus {
-		msg, err := io.ReadAll(r.Body)
-		if err != nil {
-			return fmt.Errorf("Cannot read body : %w", err)
-		}
-
-		err = ErrInvalidResponse
-		if r.StatusCode == http.StatusNotFound {
-			err = ErrNotFound
-		}
-		return fmt.Errorf("%w: %s", err, msg)
-	}
-	return nil
+func (a httpAPI) deleteItem(id int) error {
+	return a.client.Delete(context.Background(), id)
 }
@@ -38,21 +38,39 @@ var completeCmd = &cobra.Command{
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiRoot := viper.GetString("api-root")
-		return completeAction(os.Stdout, apiRoot, args[0])
+		p, err := newPrinter(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		return completeAction(os.Stdout, api, p, args[0])
 	},
 }
 
-func completeAction(out io.Writer, apiRoot, arg string) error {
+func completeAction(out io.Writer, api todoAPI, p printer, arg string) error {
 	id, err := strconv.Atoi(arg)
 	if err != nil {
 		return fmt.Errorf("%w: Item id must be a number", ErrNotNumber)
 	}
 
-	if err := completeItem(apiRoot, id); err != nil {
+	if err := api.completeItem(id); err != nil {
+		return err
+	}
+	if p.table() {
+		return printComplete(out, id)
+	}
+
+	item, err := api.getOne(id)
+	if err != nil {
 		return err
 	}
-	return printComplete(out, id)
+	return p.printItem(out, newOutputItem(id, item))
 }
 
 func printComplete(out io.Writer, id int) error {
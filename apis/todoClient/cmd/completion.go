@@ -0,0 +1,146 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionCacheTTL is how long completion reuses the cached list instead of
+	// asking the server, so pressing tab repeatedly stays fast
+	completionCacheTTL = 30 * time.Second
+	// completionTimeout bounds the single request completion sends, without retries
+	completionTimeout = 2 * time.Second
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate the shell completion script",
+	Long: `Generate the completion script for the given shell.
+
+To load completions in the current bash session:
+
+  source <(todoClient completion bash)
+
+For zsh, add the script to a directory of your $fpath:
+
+  todoClient completion zsh > "${fpath[1]}/_todoClient"
+
+For fish:
+
+  todoClient completion fish > ~/.config/fish/completions/todoClient.fish
+
+The complete, delete and view commands complete the item IDs, showing
+each item's task.`,
+	SilenceUsage:          true,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return completionAction(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+
+	completeCmd.ValidArgsFunction = completeIDs(true)
+	deleteCmd.ValidArgsFunction = completeIDs(false)
+	viewCmd.ValidArgsFunction = completeIDs(false)
+}
+
+func completionAction(out io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("%w: unsupported shell %q", ErrInvalid, shell)
+	}
+}
+
+// completeIDs completes the ID of the single argument with the items of the
+// list, only the active ones when activeOnly is set
+func completeIDs(activeOnly bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		api, closeAPI, err := openAPI(completionTimeout, 0)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		defer closeAPI()
+		// Notices would garble the shell prompt
+		api.notice = io.Discard
+
+		items, err := completionItems(api)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return idCompletions(items, toComplete, activeOnly), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionItems returns the recently cached list, or else the one of the
+// server without sending the queued changes, since pressing tab must not
+// change anything
+func completionItems(api *offlineAPI) ([]item, error) {
+	if items, ok := api.recentItems(completionCacheTTL); ok {
+		return items, nil
+	}
+	return api.peekItems()
+}
+
+// idCompletions returns the IDs starting with toComplete, annotated with the task
+func idCompletions(items []item, toComplete string, activeOnly bool) []string {
+	var completions []string
+	for k, v := range items {
+		if activeOnly && v.Done {
+			continue
+		}
+		id := strconv.Itoa(k + 1)
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		// Cobra uses the text after the tab as description
+		task := strings.Join(strings.Fields(v.Task), " ")
+		completions = append(completions, id+"\t"+task)
+	}
+	return completions
+}
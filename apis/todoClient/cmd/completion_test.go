@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDCompletions(t *testing.T) {
+	items := []item{
+		{Task: "Task 1", Done: true},
+		{Task: "Task\t2"},
+	}
+	for i := 3; i <= 11; i++ {
+		items = append(items, item{Task: "Other", Done: i%2 == 0})
+	}
+
+	testCases := []struct {
+		name       string
+		toComplete string
+		activeOnly bool
+		exp        []string
+	}{
+		{name: "Prefix", toComplete: "1", exp: []string{"1\tTask 1", "10\tOther", "11\tOther"}},
+		{name: "ActiveOnly", toComplete: "1", activeOnly: true, exp: []string{"11\tOther"}},
+		{name: "Sanitized", toComplete: "2", exp: []string{"2\tTask 2"}},
+		{name: "NoMatch", toComplete: "9x", exp: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := idCompletions(items, tc.toComplete, tc.activeOnly)
+			if strings.Join(res, "|") != strings.Join(tc.exp, "|") {
+				t.Errorf("Expect %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestRecentItems(t *testing.T) {
+	server := newFakeServer()
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &bytes.Buffer{})
+	api.now = func() time.Time { return now }
+
+	if _, ok := api.recentItems(completionCacheTTL); ok {
+		t.Fatal("Expect no items before the list is cached")
+	}
+	if _, err := api.getAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	items, ok := api.recentItems(completionCacheTTL)
+	if !ok || len(items) != 3 {
+		t.Errorf("Expect 3 cached items, got %d", len(items))
+	}
+
+	now = now.Add(completionCacheTTL + time.Second)
+	if _, ok := api.recentItems(completionCacheTTL); ok {
+		t.Error("Expect expired cache to be ignored")
+	}
+}
+
+func TestCompletionItems(t *testing.T) {
+	server := newFakeServer()
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &bytes.Buffer{})
+	api.now = func() time.Time { return now }
+
+	if _, err := api.getAll(); err != nil {
+		t.Fatal(err)
+	}
+	server.down = true
+	if err := api.addItem("Queued", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache expired and the server is back, completing must not send the queue
+	now = now.Add(completionCacheTTL + time.Second)
+	server.down = false
+	items, err := completionItems(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || len(server.items) != 3 {
+		t.Errorf("Expect 3 items and nothing sent, got %d items and %d on the server", len(items), len(server.items))
+	}
+	if ops, _ := api.journal(); len(ops) != 1 {
+		t.Errorf("Expect the queued change kept, got %v", ops)
+	}
+
+	// Unreachable, the cached list with the queued change is used
+	now = now.Add(completionCacheTTL + time.Second)
+	server.down = true
+	if items, err = completionItems(api); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || items[3].Task != "Queued" {
+		t.Errorf("Expect the cached items with the queued one, got %v", items)
+	}
+}
+
+func TestCompletionAction(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := completionAction(&out, shell); err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+			if !strings.Contains(out.String(), "todoClient") {
+				t.Errorf("Expect %s script for todoClient, got %q", shell, out.String())
+			}
+		})
+	}
+
+	if err := completionAction(os.Stdout, "tcsh"); err == nil {
+		t.Error("Expect error for unsupported shell")
+	}
+}
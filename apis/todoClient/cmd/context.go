@@ -0,0 +1,361 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// tlsOptions configures the TLS connection to a server with a private CA or
+// requiring client certificates
+type tlsOptions struct {
+	CAFile             string `yaml:"ca-file,omitempty"`
+	CertFile           string `yaml:"cert-file,omitempty"`
+	KeyFile            string `yaml:"key-file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+// clientContext is a named server profile from the config file
+type clientContext struct {
+	APIRoot string     `yaml:"api-root"`
+	Token   string     `yaml:"token,omitempty"`
+	TLS     tlsOptions `yaml:"tls,omitempty"`
+}
+
+// contextConfig is the part of the config file holding the contexts
+type contextConfig struct {
+	Current  string                   `yaml:"current-context,omitempty"`
+	Contexts map[string]clientContext `yaml:"contexts,omitempty"`
+}
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage the server contexts",
+	Long: `Manage named contexts, each holding the API root, token and TLS options
+of a server. The current context is used unless --context selects another
+one, and --api-root, TODO_API_ROOT or api-root in the config file override
+the API root of the context.`,
+}
+
+var contextListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the contexts",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return contextListAction(os.Stdout, configFile())
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Set the current context",
+	Args:              cobra.ExactArgs(1),
+	SilenceUsage:      true,
+	ValidArgsFunction: completeContexts,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return contextUseAction(os.Stdout, configFile(), args[0])
+	},
+}
+
+var contextAddCmd = &cobra.Command{
+	Use:          "add <name> <api-root>",
+	Short:        "Add or replace a context",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := clientContext{APIRoot: args[1]}
+		var err error
+		if c.Token, err = cmd.Flags().GetString("token"); err != nil {
+			return err
+		}
+		if c.TLS.CAFile, err = cmd.Flags().GetString("ca-file"); err != nil {
+			return err
+		}
+		if c.TLS.CertFile, err = cmd.Flags().GetString("cert-file"); err != nil {
+			return err
+		}
+		if c.TLS.KeyFile, err = cmd.Flags().GetString("key-file"); err != nil {
+			return err
+		}
+		if c.TLS.InsecureSkipVerify, err = cmd.Flags().GetBool("insecure-skip-verify"); err != nil {
+			return err
+		}
+		return contextAddAction(os.Stdout, configFile(), args[0], c)
+	},
+}
+
+var contextRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Short:             "Remove a context",
+	Args:              cobra.ExactArgs(1),
+	SilenceUsage:      true,
+	ValidArgsFunction: completeContexts,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return contextRemoveAction(os.Stdout, configFile(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextListCmd, contextUseCmd, contextAddCmd, contextRemoveCmd)
+
+	contextAddCmd.Flags().String("token", "", "Bearer token sent to the server")
+	contextAddCmd.Flags().String("ca-file", "", "CA certificate used to verify the server")
+	contextAddCmd.Flags().String("cert-file", "", "Client certificate")
+	contextAddCmd.Flags().String("key-file", "", "Client certificate key")
+	contextAddCmd.Flags().Bool("insecure-skip-verify", false, "Don't verify the server certificate")
+}
+
+func contextListAction(out io.Writer, file string) error {
+	cfg, _, err := loadContexts(file)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Contexts) == 0 {
+		_, err := fmt.Fprintln(out, "No contexts defined.")
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	for _, name := range names {
+		current := " "
+		if name == cfg.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", current, name, cfg.Contexts[name].APIRoot)
+	}
+	return w.Flush()
+}
+
+func contextUseAction(out io.Writer, file, name string) error {
+	cfg, raw, err := loadContexts(file)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Contexts[name]; !ok {
+		return fmt.Errorf("%w: context %q", ErrNotFound, name)
+	}
+
+	cfg.Current = name
+	if err := saveContexts(file, cfg, raw); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Switched to context %q.\n", name)
+	return err
+}
+
+func contextAddAction(out io.Writer, file, name string, c clientContext) error {
+	if name == "" || c.APIRoot == "" {
+		return fmt.Errorf("%w: context name and API root cannot be blank", ErrInvalid)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("%w: client certificate and key must be given together", ErrInvalid)
+	}
+
+	cfg, raw, err := loadContexts(file)
+	if err != nil {
+		return err
+	}
+	if cfg.Contexts == nil {
+		cfg.Contexts = map[string]clientContext{}
+	}
+	cfg.Contexts[name] = c
+	// The first context becomes the current one
+	if cfg.Current == "" {
+		cfg.Current = name
+	}
+
+	if err := saveContexts(file, cfg, raw); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Added context %q.\n", name)
+	return err
+}
+
+func contextRemoveAction(out io.Writer, file, name string) error {
+	cfg, raw, err := loadContexts(file)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Contexts[name]; !ok {
+		return fmt.Errorf("%w: context %q", ErrNotFound, name)
+	}
+
+	delete(cfg.Contexts, name)
+	if cfg.Current == name {
+		cfg.Current = ""
+	}
+	if err := saveContexts(file, cfg, raw); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Removed context %q.\n", name)
+	return err
+}
+
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, _, err := loadContexts(configFile())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for name, c := range cfg.Contexts {
+		names = append(names, name+"\t"+c.APIRoot)
+	}
+	slices.Sort(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// configFile returns the config file in use, or where to create it
+func configFile() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	if f := viper.ConfigFileUsed(); f != "" {
+		return f
+	}
+	home, err := os.UserHomeDir()
+	cobra.CheckErr(err)
+	return filepath.Join(home, ".todoClient.yaml")
+}
+
+// loadContexts reads the contexts from the config file, along with the whole
+// file so the other settings are kept when saving
+func loadContexts(file string) (contextConfig, map[string]any, error) {
+	var cfg contextConfig
+	raw := map[string]any{}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, raw, nil
+	}
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return cfg, nil, fmt.Errorf("%w: config file %s: %s", ErrInvalid, file, err)
+	}
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, nil, fmt.Errorf("%w: config file %s: %s", ErrInvalid, file, err)
+	}
+	return cfg, raw, nil
+}
+
+func saveContexts(file string, cfg contextConfig, raw map[string]any) error {
+	raw["current-context"] = cfg.Current
+	if cfg.Current == "" {
+		delete(raw, "current-context")
+	}
+	raw["contexts"] = cfg.Contexts
+	if len(cfg.Contexts) == 0 {
+		delete(raw, "contexts")
+	}
+
+	var data bytes.Buffer
+	enc := yaml.NewEncoder(&data)
+	enc.SetIndent(2)
+	if err := enc.Encode(raw); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	// The file may hold tokens
+	return os.WriteFile(file, data.Bytes(), 0o600)
+}
+
+// activeContext returns the context selected by --context or the current
+// context of the config file, nil when none is set
+func activeContext() (*clientContext, error) {
+	cfg, _, err := loadContexts(configFile())
+	if err != nil {
+		return nil, err
+	}
+
+	name := viper.GetString("context")
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	c, ok := cfg.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: context %q", ErrNotFound, name)
+	}
+	return &c, nil
+}
+
+// config returns the TLS configuration, nil when the defaults apply
+func (o tlsOptions) config() (*tls.Config, error) {
+	if o == (tlsOptions{}) {
+		return nil, nil
+	}
+
+	c := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificate found in %s", ErrInvalid, o.CAFile)
+		}
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestContextActions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".todoClient.yaml")
+	if err := os.WriteFile(file, []byte("transport: http\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	steps := []func() error{
+		func() error {
+			return contextAddAction(&out, file, "personal", clientContext{APIRoot: "http://localhost:8080"})
+		},
+		func() error {
+			return contextAddAction(&out, file, "team", clientContext{APIRoot: "https://todo.example.com", Token: "secret"})
+		},
+		func() error {
+			return contextAddAction(&out, file, "staging", clientContext{APIRoot: "https://staging.example.com"})
+		},
+		func() error { return contextUseAction(&out, file, "team") },
+		func() error { return contextRemoveAction(&out, file, "staging") },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out.Reset()
+	if err := contextListAction(&out, file); err != nil {
+		t.Fatal(err)
+	}
+	expOut := "   personal  http://localhost:8080\n*  team      https://todo.example.com\n"
+	if out.String() != expOut {
+		t.Errorf("Expect %q, got %q", expOut, out.String())
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "transport: http") {
+		t.Errorf("Expect other settings to be kept, got %q", data)
+	}
+
+	cfg, _, err := loadContexts(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Contexts["team"].Token != "secret" {
+		t.Errorf("Expect token %q, got %q", "secret", cfg.Contexts["team"].Token)
+	}
+
+	if err := contextUseAction(&out, file, "staging"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expect error %q, got %q", ErrNotFound, err)
+	}
+	if err := contextAddAction(&out, file, "bad", clientContext{APIRoot: "https://x", TLS: tlsOptions{CertFile: "cert.pem"}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expect error %q, got %q", ErrInvalid, err)
+	}
+}
+
+func TestContextList(t *testing.T) {
+	var out bytes.Buffer
+	if err := contextListAction(&out, filepath.Join(t.TempDir(), "missing.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No contexts defined.\n" {
+		t.Errorf("Expect no contexts, got %q", out.String())
+	}
+}
+
+func TestContextConnection(t *testing.T) {
+	var auth string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(testResp["resultsOne"].Status)
+		w.Write([]byte(testResp["resultsOne"].Body))
+	}))
+	defer ts.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := clientContext{APIRoot: ts.URL, Token: "secret", TLS: tlsOptions{CAFile: caFile}}
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := newHTTPAPI(c.APIRoot)
+	api.client.Token = c.Token
+	api.client.HTTPClient = newHTTPClient(0, tlsConfig)
+	if _, err := api.getOne(1); err != nil {
+		t.Fatalf("Expect no error, got %q", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expect Authorization %q, got %q", "Bearer secret", auth)
+	}
+
+	// Without the CA the server certificate isn't trusted
+	if _, err := newHTTPAPI(c.APIRoot).getOne(1); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+}
+
+func TestAPIRootPrecedence(t *testing.T) {
+	c := &clientContext{APIRoot: "https://context.example"}
+	testCases := []struct {
+		name    string
+		flag    string
+		env     string
+		config  string
+		context *clientContext
+		expRoot string
+	}{
+		{name: "FlagDefault", expRoot: "http://localhost:8080"},
+		{name: "Context", context: c, expRoot: "https://context.example"},
+		{name: "Config", config: "https://config.example", context: c, expRoot: "https://config.example"},
+		{name: "Env", env: "https://env.example", config: "https://config.example", context: c, expRoot: "https://env.example"},
+		{name: "Flag", flag: "https://flag.example", env: "https://env.example", config: "https://config.example", context: c,
+			expRoot: "https://flag.example"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+			v.SetEnvPrefix("TODO")
+			v.AutomaticEnv()
+			cmd := &cobra.Command{}
+			cmd.Flags().String("api-root", "http://localhost:8080", "")
+			if err := v.BindPFlag("api-root", cmd.Flags().Lookup("api-root")); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.flag != "" {
+				if err := cmd.Flags().Set("api-root", tc.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("TODO_API_ROOT", tc.env)
+			if tc.env == "" {
+				os.Unsetenv("TODO_API_ROOT")
+			}
+			v.SetConfigType("yaml")
+			config := ""
+			if tc.config != "" {
+				config = "api-root: " + tc.config + "\n"
+			}
+			if err := v.ReadConfig(strings.NewReader(config)); err != nil {
+				t.Fatal(err)
+			}
+
+			if root := apiRoot(v, tc.context); root != tc.expRoot {
+				t.Errorf("Expect API root %q, got %q", tc.expRoot, root)
+			}
+		})
+	}
+}
@@ -38,8 +38,18 @@ var deleteCmd = &cobra.Command{
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiRoot := viper.GetString("api-root")
-		return deleteAction(os.Stdout, apiRoot, args[0])
+		p, err := newPrinter(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		return deleteAction(os.Stdout, api, p, args[0])
 	},
 }
 
@@ -47,17 +57,28 @@ func init() {
 	rootCmd.AddCommand(deleteCmd)
 }
 
-func deleteAction(out io.Writer, apiRoot, arg string) error {
+func deleteAction(out io.Writer, api todoAPI, p printer, arg string) error {
 	id, err := strconv.Atoi(arg)
 	if err != nil {
 		return fmt.Errorf("%w: Item id must be a number", ErrNotNumber)
 	}
 
-	err = deleteItem(apiRoot, id)
+	if p.table() {
+		if err := api.deleteItem(id); err != nil {
+			return err
+		}
+		return printDelete(out, id)
+	}
+
+	// Fetch the item first, it can't be echoed once deleted
+	item, err := api.getOne(id)
 	if err != nil {
 		return err
 	}
-	return printDelete(out, id)
+	if err := api.deleteItem(id); err != nil {
+		return err
+	}
+	return p.printItem(out, newOutputItem(id, item))
 }
 
 func printDelete(out io.Writer, id int) error {
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"todo"
+)
+
+// syncPair records an item as it was after the last file sync. The server
+// assigns its own creation time, so both are needed to recognize the item
+type syncPair struct {
+	Task            string    `json:"task"`
+	LocalCreatedAt  time.Time `json:"localCreatedAt"`
+	ServerCreatedAt time.Time `json:"serverCreatedAt"`
+	Done            bool      `json:"done"`
+}
+
+// mergedItem is an item of the merged local list
+type mergedItem struct {
+	item
+	onServer        bool
+	serverCreatedAt time.Time
+}
+
+// fileSyncPlan holds the changes reconciling a local list with the server list
+type fileSyncPlan struct {
+	complete []int
+	delete   []int
+	// deleted are the pairs of the items to delete, by index in delete
+	deleted   []syncPair
+	add       []item
+	merged    []mergedItem
+	changes   []string
+	conflicts []string
+}
+
+// planFileSync merges the local and server lists. Items are matched with the
+// pairs of the last sync, then on their task and creation time. The first sync
+// has no pairs and the server assigned its own creation times, so items are
+// matched on their task alone, the duplicates in order. Completing an item
+// wins over deleting it on the other side, reported as a conflict
+func planFileSync(local, server []item, base []syncPair) fileSyncPlan {
+	var plan fileSyncPlan
+
+	type match struct {
+		l, s int
+		base *syncPair
+	}
+	var matches []match
+	usedL := make([]bool, len(local))
+	usedS := make([]bool, len(server))
+
+	firstSync := len(base) == 0
+	find := func(items []item, used []bool, task string, createdAt time.Time) int {
+		for k, i := range items {
+			if !used[k] && i.Task == task && (firstSync || i.CreatedAt.Equal(createdAt)) {
+				used[k] = true
+				return k
+			}
+		}
+		return -1
+	}
+
+	for k := range base {
+		b := &base[k]
+		l := find(local, usedL, b.Task, b.LocalCreatedAt)
+		s := find(server, usedS, b.Task, b.ServerCreatedAt)
+		if l >= 0 || s >= 0 {
+			matches = append(matches, match{l, s, b})
+		}
+	}
+	for l, i := range local {
+		if !usedL[l] {
+			usedL[l] = true
+			matches = append(matches, match{l, find(server, usedS, i.Task, i.CreatedAt), nil})
+		}
+	}
+	for s := range server {
+		if !usedS[s] {
+			matches = append(matches, match{-1, s, nil})
+		}
+	}
+
+	// Keep the local order, the items only on the server go last
+	slices.SortStableFunc(matches, func(a, b match) int {
+		switch {
+		case a.l >= 0 && b.l >= 0:
+			return a.l - b.l
+		case a.l >= 0:
+			return -1
+		case b.l >= 0:
+			return 1
+		}
+		return a.s - b.s
+	})
+
+	addToServer := func(i item) {
+		plan.add = append(plan.add, i)
+		plan.merged = append(plan.merged, mergedItem{item: i})
+		plan.changes = append(plan.changes, fmt.Sprintf("server: add %q", i.Task))
+	}
+	addToLocal := func(i item) {
+		plan.merged = append(plan.merged, mergedItem{item: i, onServer: true, serverCreatedAt: i.CreatedAt})
+		plan.changes = append(plan.changes, fmt.Sprintf("local: add %q", i.Task))
+	}
+
+	for _, m := range matches {
+		switch {
+		case m.l >= 0 && m.s >= 0:
+			l, s := local[m.l], server[m.s]
+			merged := mergedItem{item: l, onServer: true, serverCreatedAt: s.CreatedAt}
+			if s.Done && !l.Done {
+				merged.Done, merged.CompletedAt = true, s.CompletedAt
+				plan.changes = append(plan.changes, fmt.Sprintf("local: complete %q", l.Task))
+			}
+			if l.Done && !s.Done {
+				plan.complete = append(plan.complete, m.s+1)
+				plan.changes = append(plan.changes, fmt.Sprintf("server: complete %d %q", m.s+1, l.Task))
+			}
+			plan.merged = append(plan.merged, merged)
+
+		case m.l >= 0 && m.base == nil:
+			addToServer(local[m.l])
+
+		case m.l >= 0:
+			l := local[m.l]
+			if l.Done != m.base.Done {
+				plan.conflicts = append(plan.conflicts, fmt.Sprintf("%q deleted on the server but completed locally, adding it back", l.Task))
+				addToServer(l)
+				continue
+			}
+			plan.changes = append(plan.changes, fmt.Sprintf("local: delete %q", l.Task))
+
+		case m.base == nil:
+			addToLocal(server[m.s])
+
+		default:
+			s := server[m.s]
+			if s.Done != m.base.Done {
+				plan.conflicts = append(plan.conflicts, fmt.Sprintf("%q deleted locally but completed on the server, adding it back", s.Task))
+				addToLocal(s)
+				continue
+			}
+			plan.delete = append(plan.delete, m.s+1)
+			plan.deleted = append(plan.deleted, *m.base)
+			plan.changes = append(plan.changes, fmt.Sprintf("server: delete %d %q", m.s+1, s.Task))
+		}
+	}
+
+	return plan
+}
+
+// apply sends the changes to the server. Completions go first and deletions
+// from the highest ID down so the IDs in the plan stay valid. When a change
+// fails, it returns the pairs of the deletions not applied with the error,
+// for the next sync to delete them again
+func (p fileSyncPlan) apply(api todoAPI, serverLen int) ([]syncPair, error) {
+	for _, id := range p.complete {
+		if err := api.completeItem(id); err != nil {
+			return p.deleted, err
+		}
+	}
+
+	deletes := slices.Clone(p.delete)
+	slices.Sort(deletes)
+	for k, id := range slices.Backward(deletes) {
+		if err := api.deleteItem(id); err != nil {
+			var pending []syncPair
+			for _, id := range deletes[:k+1] {
+				pending = append(pending, p.deleted[slices.Index(p.delete, id)])
+			}
+			return pending, err
+		}
+	}
+
+	id := serverLen - len(deletes)
+	for _, i := range p.add {
+		if err := api.addItem(i.Task, i.Due); err != nil {
+			return nil, err
+		}
+		id++
+		if i.Done {
+			if err := api.completeItem(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+// syncState returns the pairs of the merged list, taking the creation time
+// of the items just added to the server from the server list
+func (p fileSyncPlan) syncState(server []item) []syncPair {
+	known := map[int64]bool{}
+	for _, m := range p.merged {
+		if m.onServer {
+			known[m.serverCreatedAt.UnixNano()] = true
+		}
+	}
+	var added []item
+	for _, s := range server {
+		if !known[s.CreatedAt.UnixNano()] {
+			added = append(added, s)
+		}
+	}
+
+	pairs := make([]syncPair, 0, len(p.merged))
+	for _, m := range p.merged {
+		serverCreatedAt := m.serverCreatedAt
+		if !m.onServer {
+			k := slices.IndexFunc(added, func(s item) bool { return s.Task == m.Task })
+			if k < 0 {
+				continue
+			}
+			serverCreatedAt = added[k].CreatedAt
+			added = slices.Delete(added, k, k+1)
+		}
+		pairs = append(pairs, syncPair{
+			Task:            m.Task,
+			LocalCreatedAt:  m.CreatedAt,
+			ServerCreatedAt: serverCreatedAt,
+			Done:            m.Done,
+		})
+	}
+	return pairs
+}
+
+// fileSyncAction reconciles the todo CLI list in file with the server list,
+// keeping the state of the last sync in stateFile
+func fileSyncAction(out io.Writer, api todoAPI, file, stateFile string, dryRun bool) error {
+	l := &todo.List{}
+	if err := l.Get(file); err != nil {
+		return err
+	}
+	local := make([]item, 0, len(l.Items))
+	for _, i := range l.Items {
+		local = append(local, item{Task: i.Task, Done: i.Done, CreatedAt: i.CreatedAt, CompletedAt: i.CompletedAt, Due: i.Due})
+	}
+
+	server, err := api.getAll()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var base []syncPair
+	if err := readJSON(stateFile, &base); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	plan := planFileSync(local, server, base)
+	for _, c := range plan.changes {
+		if _, err := fmt.Fprintln(out, c); err != nil {
+			return err
+		}
+	}
+	for _, c := range plan.conflicts {
+		if _, err := fmt.Fprintf(out, "conflict: %s\n", c); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		_, err := fmt.Fprintf(out, "dry run: %d change(s) not applied\n", len(plan.changes))
+		return err
+	}
+
+	// The changes applied are saved even when one fails, or the next sync
+	// would add again the items already sent
+	pending, applyErr := plan.apply(api, len(server))
+	if server, err = api.getAll(); err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Join(applyErr, err)
+	}
+
+	l.Items = l.Items[:0]
+	for _, m := range plan.merged {
+		l.Items = append(l.Items, todo.Item{Task: m.Task, Done: m.Done, CreatedAt: m.CreatedAt, CompletedAt: m.CompletedAt, Due: m.Due})
+	}
+	if err := l.Save(file); err != nil {
+		return err
+	}
+	if err := writeJSON(stateFile, append(plan.syncState(server), pending...)); err != nil {
+		return err
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+
+	if len(plan.changes) == 0 {
+		_, err := fmt.Fprintln(out, "Already in sync.")
+		return err
+	}
+	_, err = fmt.Fprintf(out, "sync: %d change(s) applied\n", len(plan.changes))
+	return err
+}
+
+// fileSyncStateFile returns where the state of the sync of file with the server is kept
+func (a *offlineAPI) fileSyncStateFile(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(a.cacheFile, ".cache.json")
+	return base + "_" + unsafeChars.ReplaceAllString(abs, "_") + ".filesync.json", nil
+}
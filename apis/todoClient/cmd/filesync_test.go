@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todo"
+)
+
+func TestFileSync(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".todo.json")
+	stateFile := filepath.Join(dir, "state.json")
+
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	l := &todo.List{Items: []todo.Item{
+		{Task: "Local A", CreatedAt: created},
+		{Task: "Local B", CreatedAt: created.Add(time.Minute), Done: true, CompletedAt: created.Add(time.Hour)},
+	}}
+	if err := l.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeAPI{items: []item{{Task: "Server C", CreatedAt: created.Add(2 * time.Minute)}}}
+
+	// listState returns both lists as "task:done" strings
+	listState := func() (string, string) {
+		t.Helper()
+		l := &todo.List{}
+		if err := l.Get(file); err != nil {
+			t.Fatal(err)
+		}
+		var local, remote []string
+		for _, i := range l.Items {
+			local = append(local, i.Task+":"+map[bool]string{true: "X", false: "-"}[i.Done])
+		}
+		for _, i := range server.items {
+			remote = append(remote, i.Task+":"+map[bool]string{true: "X", false: "-"}[i.Done])
+		}
+		return strings.Join(local, ","), strings.Join(remote, ",")
+	}
+
+	steps := []struct {
+		name      string
+		change    func()
+		dryRun    bool
+		expOut    string
+		expLocal  string
+		expServer string
+	}{
+		{name: "DryRun", dryRun: true,
+			expOut:   "server: add \"Local A\"\nserver: add \"Local B\"\nlocal: add \"Server C\"\ndry run: 3 change(s) not applied\n",
+			expLocal: "Local A:-,Local B:X", expServer: "Server C:-"},
+		{name: "FirstSync",
+			expOut:   "server: add \"Local A\"\nserver: add \"Local B\"\nlocal: add \"Server C\"\nsync: 3 change(s) applied\n",
+			expLocal: "Local A:-,Local B:X,Server C:-", expServer: "Server C:-,Local A:-,Local B:X"},
+		{name: "InSync", expOut: "Already in sync.\n",
+			expLocal: "Local A:-,Local B:X,Server C:-", expServer: "Server C:-,Local A:-,Local B:X"},
+		{name: "BothSides",
+			change: func() {
+				l := &todo.List{}
+				l.Get(file)
+				l.Delete(1)
+				l.Add("Local D")
+				l.Save(file)
+				server.completeItem(1)
+				server.deleteItem(3)
+			},
+			expOut: "local: delete \"Local B\"\nlocal: complete \"Server C\"\nserver: add \"Local D\"\n" +
+				"server: delete 2 \"Local A\"\nsync: 4 change(s) applied\n",
+			expLocal: "Server C:X,Local D:-", expServer: "Server C:X,Local D:-"},
+		{name: "Conflict",
+			change: func() {
+				l := &todo.List{}
+				l.Get(file)
+				l.Complete(2)
+				l.Save(file)
+				server.deleteItem(2)
+			},
+			expOut: "server: add \"Local D\"\n" +
+				"conflict: \"Local D\" deleted on the server but completed locally, adding it back\nsync: 1 change(s) applied\n",
+			expLocal: "Server C:X,Local D:X", expServer: "Server C:X,Local D:X"},
+	}
+
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			if s.change != nil {
+				s.change()
+			}
+
+			var out bytes.Buffer
+			if err := fileSyncAction(&out, server, file, stateFile, s.dryRun); err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+			if out.String() != s.expOut {
+				t.Errorf("Expect output %q, got %q", s.expOut, out.String())
+			}
+
+			local, remote := listState()
+			if local != s.expLocal {
+				t.Errorf("Expect local list %q, got %q", s.expLocal, local)
+			}
+			if remote != s.expServer {
+				t.Errorf("Expect server list %q, got %q", s.expServer, remote)
+			}
+		})
+	}
+}
+
+func TestFileSyncFirstMatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".todo.json")
+	stateFile := filepath.Join(dir, "state.json")
+
+	// The same items were added on both sides, the server set its own creation times
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	l := &todo.List{Items: []todo.Item{
+		{Task: "Shared", CreatedAt: created},
+		{Task: "Twice", CreatedAt: created.Add(time.Minute), Done: true, CompletedAt: created.Add(time.Hour)},
+		{Task: "Twice", CreatedAt: created.Add(2 * time.Minute)},
+	}}
+	if err := l.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeAPI{items: []item{
+		{Task: "Twice", CreatedAt: created.Add(24 * time.Hour)},
+		{Task: "Shared", CreatedAt: created.Add(25 * time.Hour)},
+	}}
+
+	var out bytes.Buffer
+	if err := fileSyncAction(&out, server, file, stateFile, false); err != nil {
+		t.Fatal(err)
+	}
+	expOut := "server: complete 1 \"Twice\"\nserver: add \"Twice\"\nsync: 2 change(s) applied\n"
+	if out.String() != expOut {
+		t.Errorf("Expect output %q, got %q", expOut, out.String())
+	}
+
+	var tasks []string
+	for _, i := range server.items {
+		tasks = append(tasks, i.Task+":"+map[bool]string{true: "X", false: "-"}[i.Done])
+	}
+	if exp := "Twice:X,Shared:-,Twice:-"; strings.Join(tasks, ",") != exp {
+		t.Errorf("Expect server items %q, got %q", exp, strings.Join(tasks, ","))
+	}
+
+	out.Reset()
+	if err := fileSyncAction(&out, server, file, stateFile, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Already in sync.\n" {
+		t.Errorf("Expect lists in sync, got %q", out.String())
+	}
+}
+
+func TestFileSyncDue(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".todo.json")
+
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	localDue := time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)
+	serverDue := time.Date(2024, 4, 1, 23, 59, 59, 0, time.UTC)
+	l := &todo.List{Items: []todo.Item{{Task: "Local", CreatedAt: created, Due: localDue}}}
+	if err := l.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeAPI{items: []item{{Task: "Server", CreatedAt: created, Due: serverDue}}}
+
+	var out bytes.Buffer
+	if err := fileSyncAction(&out, server, file, filepath.Join(dir, "state.json"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.items) != 2 || !server.items[1].Due.Equal(localDue) {
+		t.Errorf("Expect the local due date sent, got %v", server.items)
+	}
+	if err := l.Get(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Items) != 2 || !l.Items[1].Due.Equal(serverDue) {
+		t.Errorf("Expect the server due date kept, got %v", l.Items)
+	}
+}
+
+// flakyAPI fails the nth add and the nth delete, counting from 1
+type flakyAPI struct {
+	*fakeAPI
+	failAdd, failDelete int
+}
+
+func (f *flakyAPI) addItem(task string, due time.Time) error {
+	if f.failAdd--; f.failAdd == 0 {
+		return ErrConnection
+	}
+	return f.fakeAPI.addItem(task, due)
+}
+
+func (f *flakyAPI) deleteItem(id int) error {
+	if f.failDelete--; f.failDelete == 0 {
+		return ErrConnection
+	}
+	return f.fakeAPI.deleteItem(id)
+}
+
+func TestFileSyncApplyFailure(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".todo.json")
+	stateFile := filepath.Join(dir, "state.json")
+
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	l := &todo.List{Items: []todo.Item{{Task: "A", CreatedAt: created}, {Task: "B", CreatedAt: created.Add(time.Minute)}}}
+	if err := l.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeAPI{}
+	if err := fileSyncAction(io.Discard, server, file, stateFile, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// change edits the local list then syncs it through api
+	change := func(api todoAPI, edit func(l *todo.List)) (string, error) {
+		t.Helper()
+		l := &todo.List{}
+		if err := l.Get(file); err != nil {
+			t.Fatal(err)
+		}
+		edit(l)
+		if err := l.Save(file); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		err := fileSyncAction(&out, api, file, stateFile, false)
+		return out.String(), err
+	}
+	tasks := func(items []item) string {
+		var s []string
+		for _, i := range items {
+			s = append(s, i.Task)
+		}
+		return strings.Join(s, ",")
+	}
+
+	// The add of D fails once A is deleted and C added
+	_, err := change(&flakyAPI{fakeAPI: server, failAdd: 2}, func(l *todo.List) {
+		l.Delete(1)
+		l.Add("C")
+		l.Add("D")
+	})
+	if !errors.Is(err, ErrConnection) {
+		t.Fatalf("Expect error %q, got %q", ErrConnection, err)
+	}
+	if got := tasks(server.items); got != "B,C" {
+		t.Fatalf("Expect server list %q, got %q", "B,C", got)
+	}
+
+	// The delete of B fails
+	if _, err := change(&flakyAPI{fakeAPI: server, failDelete: 1}, func(l *todo.List) { l.Delete(1) }); !errors.Is(err, ErrConnection) {
+		t.Fatalf("Expect error %q, got %q", ErrConnection, err)
+	}
+
+	// The next sync sends the changes not applied only
+	out, err := change(server, func(l *todo.List) {})
+	if err != nil {
+		t.Fatalf("Expect no error, got %q", err)
+	}
+	expOut := "server: add \"D\"\nserver: delete 1 \"B\"\nsync: 2 change(s) applied\n"
+	if out != expOut {
+		t.Errorf("Expect output %q, got %q", expOut, out)
+	}
+	if got := tasks(server.items); got != "C,D" {
+		t.Errorf("Expect server list %q, got %q", "C,D", got)
+	}
+	l = &todo.List{}
+	if err := l.Get(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Items) != 2 || l.Items[0].Task != "C" || l.Items[1].Task != "D" {
+		t.Errorf("Expect local list C,D, got %v", l.Items)
+	}
+}
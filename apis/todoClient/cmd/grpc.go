@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"apis/todopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcAPI talks to the todo gRPC service
+type grpcAPI struct {
+	conn    *grpc.ClientConn
+	client  todopb.TodoServiceClient
+	timeout time.Duration
+}
+
+// newGRPCAPI connects to the service at addr, with the TLS options and the
+// token of the context c when set
+func newGRPCAPI(addr string, timeout time.Duration, c *clientContext) (*grpcAPI, error) {
+	if timeout <= 0 {
+		timeout = clientTimeout
+	}
+
+	opts, err := grpcCredentials(c)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrConnection, err)
+	}
+
+	return &grpcAPI{
+		conn:    conn,
+		client:  todopb.NewTodoServiceClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// grpcCredentials returns the dial options securing the connection like the
+// context c does the REST one: over TLS when it sets TLS options or an https
+// API root, sending its token along. The token is never sent in clear text
+func grpcCredentials(c *clientContext) ([]grpc.DialOption, error) {
+	if c == nil {
+		return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil && strings.HasPrefix(c.APIRoot, "https://") {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig == nil {
+		if c.Token != "" {
+			return nil, fmt.Errorf("%w: the context token requires TLS over gRPC, set its TLS options or an https API root", ErrInvalid)
+		}
+		return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	if c.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(c.Token)))
+	}
+	return opts, nil
+}
+
+// tokenCredentials sends the token as a bearer token with each call, like
+// the REST client does
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+func (a *grpcAPI) close() error {
+	return a.conn.Close()
+}
+
+func (a *grpcAPI) getAll() ([]item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	resp, err := a.client.ListTodos(ctx, &todopb.ListTodosRequest{})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if len(resp.GetTodos()) == 0 {
+		return nil, fmt.Errorf("%w: No results found", ErrNotFound)
+	}
+
+	items := make([]item, 0, len(resp.GetTodos()))
+	for _, t := range resp.GetTodos() {
+		items = append(items, pbItem(t))
+	}
+	return items, nil
+}
+
+func (a *grpcAPI) getOne(id int) (item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	t, err := a.client.GetTodo(ctx, &todopb.GetTodoRequest{Id: int64(id)})
+	if err != nil {
+		return item{}, grpcError(err)
+	}
+	return pbItem(t), nil
+}
+
+func (a *grpcAPI) addItem(task string, due time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	req := &todopb.AddTodoRequest{Task: task}
+	if !due.IsZero() {
+		req.Due = timestamppb.New(due)
+	}
+	_, err := a.client.AddTodo(ctx, req)
+	return grpcError(err)
+}
+
+func (a *grpcAPI) completeItem(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	_, err := a.client.CompleteTodo(ctx, &todopb.CompleteTodoRequest{Id: int64(id)})
+	return grpcError(err)
+}
+
+func (a *grpcAPI) deleteItem(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	_, err := a.client.DeleteTodo(ctx, &todopb.DeleteTodoRequest{Id: int64(id)})
+	return grpcError(err)
+}
+
+func pbItem(t *todopb.Todo) item {
+	i := item{
+		Task:      t.GetTask(),
+		Done:      t.GetDone(),
+		CreatedAt: t.GetCreatedAt().AsTime(),
+	}
+	if t.GetCompletedAt() != nil {
+		i.CompletedAt = t.GetCompletedAt().AsTime()
+	}
+	if t.GetDue() != nil {
+		i.Due = t.GetDue().AsTime()
+	}
+	return i
+}
+
+// grpcError maps the gRPC status codes to the client errors
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	s := status.Convert(err)
+	switch s.Code() {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, s.Message())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalid, s.Message())
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", ErrConnection, s.Message())
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidResponse, s.Message())
+	}
+}
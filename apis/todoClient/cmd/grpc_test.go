@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"apis/todopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeTodoService answers with a fixed list of todos
+type fakeTodoService struct {
+	todopb.UnimplementedTodoServiceServer
+	todos []*todopb.Todo
+}
+
+func (f *fakeTodoService) ListTodos(context.Context, *todopb.ListTodosRequest) (*todopb.ListTodosResponse, error) {
+	return &todopb.ListTodosResponse{Todos: f.todos}, nil
+}
+
+func (f *fakeTodoService) GetTodo(ctx context.Context, req *todopb.GetTodoRequest) (*todopb.Todo, error) {
+	if req.GetId() < 1 || int(req.GetId()) > len(f.todos) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return f.todos[req.GetId()-1], nil
+}
+
+func (f *fakeTodoService) CompleteTodo(ctx context.Context, req *todopb.CompleteTodoRequest) (*todopb.Todo, error) {
+	return nil, status.Error(codes.InvalidArgument, "invalid ID")
+}
+
+func mockGRPCServer(t *testing.T, svc todopb.TodoServiceServer) *grpcAPI {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	todopb.RegisterTodoServiceServer(s, svc)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	api, err := newGRPCAPI(l.Addr().String(), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { api.close() })
+	return api
+}
+
+func TestGRPCTransport(t *testing.T) {
+	created := time.Date(2019, 10, 28, 8, 23, 38, 0, time.UTC)
+	api := mockGRPCServer(t, &fakeTodoService{todos: []*todopb.Todo{
+		{Id: 1, Task: "Task 1", CreatedAt: timestamppb.New(created), Due: timestamppb.New(created.AddDate(0, 0, 1))},
+		{Id: 2, Task: "Task 2", Done: true, CreatedAt: timestamppb.New(created), CompletedAt: timestamppb.New(created)},
+	}})
+
+	t.Run("List", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := listAction(&out, api, printer{}, false); err != nil {
+			t.Fatal(err)
+		}
+		expOut := "-  1  Task 1  due Oct/29 @08:23\nX  2  Task 2\n"
+		if out.String() != expOut {
+			t.Errorf("Expect output %q, got %q", expOut, out.String())
+		}
+	})
+
+	t.Run("ViewNotFound", func(t *testing.T) {
+		var out bytes.Buffer
+		err := viewAction(&out, api, printer{}, "3")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expect error %q, got %q", ErrNotFound, err)
+		}
+	})
+
+	t.Run("CompleteInvalid", func(t *testing.T) {
+		var out bytes.Buffer
+		err := completeAction(&out, api, printer{}, "1")
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expect error %q, got %q", ErrInvalid, err)
+		}
+	})
+}
+
+func TestGRPCContext(t *testing.T) {
+	// Borrow the certificate of a TLS test server for the gRPC one
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	ts.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth []string
+	s := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: ts.TLS.Certificates})),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			auth = md.Get("authorization")
+			return handler(ctx, req)
+		}),
+	)
+	todopb.RegisterTodoServiceServer(s, &fakeTodoService{todos: []*todopb.Todo{{Id: 1, Task: "Task 1"}}})
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	c := &clientContext{APIRoot: "https://localhost", Token: "secret", TLS: tlsOptions{CAFile: caFile}}
+	api, err := newGRPCAPI(l.Addr().String(), 0, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer api.close()
+
+	if _, err := api.getAll(); err != nil {
+		t.Fatalf("Expect no error, got %q", err)
+	}
+	if len(auth) != 1 || auth[0] != "Bearer secret" {
+		t.Errorf("Expect authorization %q, got %q", "Bearer secret", auth)
+	}
+
+	// The token isn't sent in clear text
+	_, err = newGRPCAPI(l.Addr().String(), 0, &clientContext{APIRoot: "http://localhost", Token: "secret"})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expect error %q, got %q", ErrInvalid, err)
+	}
+}
@@ -44,7 +44,7 @@ func TestIntegration(t *testing.T) {
 		expOut := fmt.Sprintf("Added task %q to the list.\n", task)
 
 		var out bytes.Buffer
-		if err := addAction(&out, apiRoot, args); err != nil {
+		if err := addAction(&out, newHTTPAPI(apiRoot), printer{}, args, ""); err != nil {
 			t.Fatal(err)
 		}
 
@@ -55,7 +55,7 @@ func TestIntegration(t *testing.T) {
 
 	t.Run("ListTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := listAction(&out, apiRoot, false); err != nil {
+		if err := listAction(&out, newHTTPAPI(apiRoot), printer{}, false); err != nil {
 			t.Fatal(err)
 		}
 
@@ -80,7 +80,7 @@ func TestIntegration(t *testing.T) {
 
 	viewRes := t.Run("ViewTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := viewAction(&out, apiRoot, taskId); err != nil {
+		if err := viewAction(&out, newHTTPAPI(apiRoot), printer{}, taskId); err != nil {
 			t.Fatal(err)
 		}
 
@@ -102,7 +102,7 @@ func TestIntegration(t *testing.T) {
 
 	t.Run("CompleteTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := completeAction(&out, apiRoot, taskId); err != nil {
+		if err := completeAction(&out, newHTTPAPI(apiRoot), printer{}, taskId); err != nil {
 			t.Fatal(err)
 		}
 
@@ -112,7 +112,7 @@ func TestIntegration(t *testing.T) {
 
 	t.Run("ListCompletedTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := listAction(&out, apiRoot, false); err != nil {
+		if err := listAction(&out, newHTTPAPI(apiRoot), printer{}, false); err != nil {
 			t.Fatal(err)
 		}
 		outList := ""
@@ -131,7 +131,7 @@ func TestIntegration(t *testing.T) {
 	})
 	t.Run("DeleteTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := deleteAction(&out, apiRoot, taskId); err != nil {
+		if err := deleteAction(&out, newHTTPAPI(apiRoot), printer{}, taskId); err != nil {
 			t.Fatal(err)
 		}
 
@@ -142,7 +142,7 @@ func TestIntegration(t *testing.T) {
 
 	t.Run("ListDeleteTask", func(t *testing.T) {
 		var out bytes.Buffer
-		if err := listAction(&out, apiRoot, false); err != nil {
+		if err := listAction(&out, newHTTPAPI(apiRoot), printer{}, false); err != nil {
 			t.Fatal(err)
 		}
 
@@ -36,12 +36,22 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List Todos",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiRoot := viper.GetString("api-root")
+		p, err := newPrinter(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
 		isActive, err := cmd.Flags().GetBool("active")
 		if err != nil {
 			return err
 		}
-		return listAction(os.Stdout, apiRoot, isActive)
+		return listAction(os.Stdout, api, p, isActive)
 	},
 }
 
@@ -50,12 +60,24 @@ func init() {
 	listCmd.Flags().Bool("active", false, "Show only active task")
 }
 
-func listAction(out io.Writer, url string, isActive bool) error {
-	items, err := getAll(url)
+func listAction(out io.Writer, api todoAPI, p printer, isActive bool) error {
+	items, err := api.getAll()
 	if err != nil {
 		return err
 	}
-	return printAll(out, items, isActive)
+	if p.table() {
+		return printAll(out, items, isActive)
+	}
+
+	// Items keep their list position as ID even when filtered out
+	output := []outputItem{}
+	for k, v := range items {
+		if isActive && v.Done {
+			continue
+		}
+		output = append(output, newOutputItem(k+1, v))
+	}
+	return p.printItems(out, output)
 }
 
 func printAll(out io.Writer, items []item, isActive bool) error {
@@ -68,6 +90,10 @@ func printAll(out io.Writer, items []item, isActive bool) error {
 		if isActive && v.Done {
 			continue
 		}
+		if !v.Due.IsZero() {
+			fmt.Fprintf(w, "%s\t%d\t%s\t  due %s\t\n", done, k+1, v.Task, v.Due.Format(timeFormat))
+			continue
+		}
 		fmt.Fprintf(w, "%s\t%d\t%s\t\n", done, k+1, v.Task)
 	}
 
@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"apis/todoClient/todoapi"
+)
+
+const (
+	opAdd      = "add"
+	opComplete = "complete"
+	opDelete   = "delete"
+)
+
+// queuedOp is an operation recorded in the journal while the server was unreachable.
+// Complete and delete operations also record the targeted item so they can be
+// matched against the server list when replayed, even if the IDs shifted
+type queuedOp struct {
+	Op        string    `json:"op"`
+	ID        int       `json:"id,omitempty"`
+	Task      string    `json:"task"`
+	CreatedAt time.Time `json:"createdAt"`
+	QueuedAt  time.Time `json:"queuedAt"`
+	Due       time.Time `json:"due,omitzero"`
+}
+
+func (op queuedOp) String() string {
+	if op.Op == opAdd {
+		return fmt.Sprintf("%s %q", op.Op, op.Task)
+	}
+	return fmt.Sprintf("%s %d %q", op.Op, op.ID, op.Task)
+}
+
+type cachedList struct {
+	Date  time.Time `json:"date"`
+	Items []item    `json:"items"`
+}
+
+// syncReport summarizes a journal replay
+type syncReport struct {
+	Replayed  int
+	Pending   int
+	Conflicts []string
+}
+
+// offlineAPI wraps a todoAPI with a local cache of the last fetched list and a
+// journal of the changes made while the server is unreachable. Notices about
+// stale data and replayed changes go to notice so they never mix with the output
+type offlineAPI struct {
+	api         todoAPI
+	cacheFile   string
+	journalFile string
+	notice      io.Writer
+	now         func() time.Time
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// newOfflineAPI stores the cache and journal of the server identified by name in dir
+func newOfflineAPI(api todoAPI, dir, name string, notice io.Writer) *offlineAPI {
+	base := unsafeChars.ReplaceAllString(name, "_")
+	return &offlineAPI{
+		api:         api,
+		cacheFile:   filepath.Join(dir, base+".cache.json"),
+		journalFile: filepath.Join(dir, base+".journal.json"),
+		notice:      notice,
+		now:         time.Now,
+	}
+}
+
+func (a *offlineAPI) getAll() ([]item, error) {
+	a.autoReplay()
+
+	items, err := a.api.getAll()
+	switch {
+	case err == nil:
+		return items, a.saveCache(items)
+	case errors.Is(err, ErrNotFound):
+		return nil, errors.Join(err, a.saveCache(nil))
+	case !errors.Is(err, ErrConnection):
+		return nil, err
+	}
+
+	cached, cerr := a.cachedItems(true)
+	if cerr != nil {
+		return nil, err
+	}
+	if len(cached) == 0 {
+		return nil, fmt.Errorf("%w: No results found in cache", ErrNotFound)
+	}
+	return cached, nil
+}
+
+func (a *offlineAPI) getOne(id int) (item, error) {
+	a.autoReplay()
+
+	i, err := a.api.getOne(id)
+	if !errors.Is(err, ErrConnection) {
+		return i, err
+	}
+
+	cached, cerr := a.cachedItems(true)
+	if cerr != nil {
+		return item{}, err
+	}
+	if id < 1 || id > len(cached) {
+		return item{}, fmt.Errorf("%w: item %d not in cache", ErrNotFound, id)
+	}
+	return cached[id-1], nil
+}
+
+func (a *offlineAPI) addItem(task string, due time.Time) error {
+	a.autoReplay()
+
+	err := a.api.addItem(task, due)
+	if !errors.Is(err, ErrConnection) {
+		return err
+	}
+	return a.enqueue(queuedOp{Op: opAdd, Task: task, Due: due})
+}
+
+func (a *offlineAPI) completeItem(id int) error {
+	return a.mutate(opComplete, id, a.api.completeItem)
+}
+
+func (a *offlineAPI) deleteItem(id int) error {
+	return a.mutate(opDelete, id, a.api.deleteItem)
+}
+
+func (a *offlineAPI) mutate(op string, id int, f func(int) error) error {
+	a.autoReplay()
+
+	err := f(id)
+	if !errors.Is(err, ErrConnection) {
+		return err
+	}
+
+	cached, cerr := a.cachedItems(false)
+	if cerr != nil {
+		return err
+	}
+	if id < 1 || id > len(cached) {
+		return fmt.Errorf("%w: item %d not in cache", ErrNotFound, id)
+	}
+
+	target := cached[id-1]
+	return a.enqueue(queuedOp{Op: op, ID: id, Task: target.Task, CreatedAt: target.CreatedAt})
+}
+
+// cachedItems returns the cached list with the queued operations applied.
+// When stale is set it prints the stale marker, the items being shown to the user
+func (a *offlineAPI) cachedItems(stale bool) ([]item, error) {
+	var c cachedList
+	if err := readJSON(a.cacheFile, &c); err != nil {
+		return nil, err
+	}
+	ops, err := a.journal()
+	if err != nil {
+		return nil, err
+	}
+
+	items := c.Items
+	for _, op := range ops {
+		switch op.Op {
+		case opAdd:
+			items = append(items, item{Task: op.Task, CreatedAt: op.QueuedAt, Due: op.Due})
+		case opComplete:
+			if k := findItem(items, op); k >= 0 {
+				items[k].Done = true
+				items[k].CompletedAt = op.QueuedAt
+			}
+		case opDelete:
+			if k := findItem(items, op); k >= 0 {
+				items = append(items[:k], items[k+1:]...)
+			}
+		}
+	}
+
+	if stale {
+		fmt.Fprintf(a.notice, "stale: server unreachable, using cached list from %s", c.Date.Format(timeFormat))
+		if len(ops) > 0 {
+			fmt.Fprintf(a.notice, " with %d queued change(s)", len(ops))
+		}
+		fmt.Fprintln(a.notice)
+	}
+	return items, nil
+}
+
+// recentItems returns the cached list with the queued operations applied when
+// it was fetched less than maxAge ago
+func (a *offlineAPI) recentItems(maxAge time.Duration) ([]item, bool) {
+	var c cachedList
+	if err := readJSON(a.cacheFile, &c); err != nil || a.now().Sub(c.Date) > maxAge {
+		return nil, false
+	}
+	items, err := a.cachedItems(false)
+	if err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+// peekItems returns the list like getAll without replaying the journal first,
+// falling back to the cached list when the server fails
+func (a *offlineAPI) peekItems() ([]item, error) {
+	items, err := a.api.getAll()
+	switch {
+	case err == nil:
+		return items, a.saveCache(items)
+	case errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	cached, cerr := a.cachedItems(true)
+	if cerr != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+func (a *offlineAPI) enqueue(op queuedOp) error {
+	op.QueuedAt = a.now()
+
+	ops, err := a.journal()
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(a.journalFile, append(ops, op)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.notice, "queued: server unreachable, %s will be sent on next sync\n", op)
+	return nil
+}
+
+// autoReplay replays the journal before talking to the server, ignoring
+// connection errors since the operation itself will report them
+func (a *offlineAPI) autoReplay() {
+	ops, err := a.journal()
+	if err != nil || len(ops) == 0 {
+		return
+	}
+
+	report, err := a.replay()
+	if err != nil && !errors.Is(err, ErrConnection) {
+		fmt.Fprintf(a.notice, "sync: %v\n", err)
+	}
+	if report.Replayed > 0 || len(report.Conflicts) > 0 {
+		printReport(a.notice, report)
+	}
+}
+
+// replay sends the queued operations to the server in order. Operations the
+// server refuses, like when their target can't be found anymore, are reported
+// as conflicts and dropped. When the server is unreachable or fails to handle
+// an operation, it and the remaining operations stay in the journal
+func (a *offlineAPI) replay() (syncReport, error) {
+	var report syncReport
+
+	ops, err := a.journal()
+	if err != nil || len(ops) == 0 {
+		return report, err
+	}
+
+	// Items added offline only get their server creation time once replayed
+	created := map[int64]time.Time{}
+
+	for k, op := range ops {
+		if t, ok := created[op.CreatedAt.UnixNano()]; ok {
+			op.CreatedAt = t
+		}
+
+		conflict, err := a.replayOp(op, created)
+		if err != nil && !isConflict(err) {
+			report.Pending = len(ops) - k
+			return report, errors.Join(err, writeJSON(a.journalFile, ops[k:]))
+		}
+		if err != nil {
+			conflict = err.Error()
+		}
+
+		if conflict != "" {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s: %s", op, conflict))
+			continue
+		}
+		report.Replayed++
+	}
+
+	return report, os.Remove(a.journalFile)
+}
+
+// replayOp sends a single operation, returning a conflict description when the
+// server state doesn't allow applying it
+func (a *offlineAPI) replayOp(op queuedOp, created map[int64]time.Time) (string, error) {
+	if op.Op == opAdd {
+		if err := a.api.addItem(op.Task, op.Due); err != nil {
+			return "", err
+		}
+		// The server appends new items, remember the creation time it assigned
+		if items, err := a.api.getAll(); err == nil && len(items) > 0 && items[len(items)-1].Task == op.Task {
+			created[op.QueuedAt.UnixNano()] = items[len(items)-1].CreatedAt
+		}
+		return "", nil
+	}
+
+	items, err := a.api.getAll()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+
+	k := findItem(items, op)
+	if k < 0 {
+		return "item no longer exists on the server", nil
+	}
+
+	switch op.Op {
+	case opComplete:
+		if items[k].Done {
+			return "item already completed on the server", nil
+		}
+		return "", a.api.completeItem(k + 1)
+	case opDelete:
+		return "", a.api.deleteItem(k + 1)
+	default:
+		return fmt.Sprintf("unknown operation %q", op.Op), nil
+	}
+}
+
+// isConflict tells if the server refused the operation for good, as opposed
+// to failing to handle it for now, like on a 500 or a 429 reply
+func isConflict(err error) bool {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+		return true
+	}
+	var apiErr *todoapi.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+func (a *offlineAPI) journal() ([]queuedOp, error) {
+	var ops []queuedOp
+	err := readJSON(a.journalFile, &ops)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return ops, err
+}
+
+func (a *offlineAPI) saveCache(items []item) error {
+	return writeJSON(a.cacheFile, cachedList{Date: a.now(), Items: items})
+}
+
+// findItem returns the index of the item targeted by op, matching on the task
+// and creation time first, then on the task alone when it is unique
+func findItem(items []item, op queuedOp) int {
+	match, count := -1, 0
+	for k, i := range items {
+		if i.Task != op.Task {
+			continue
+		}
+		if i.CreatedAt.Equal(op.CreatedAt) {
+			return k
+		}
+		match = k
+		count++
+	}
+
+	if count != 1 {
+		return -1
+	}
+	return match
+}
+
+func printReport(out io.Writer, r syncReport) error {
+	if _, err := fmt.Fprintf(out, "sync: %d change(s) replayed\n", r.Replayed); err != nil {
+		return err
+	}
+	for _, c := range r.Conflicts {
+		if _, err := fmt.Fprintf(out, "conflict: %s\n", c); err != nil {
+			return err
+		}
+	}
+	if r.Pending > 0 {
+		if _, err := fmt.Fprintf(out, "sync: %d change(s) still queued\n", r.Pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSON(file string, v any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSON(file string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"apis/todoClient/todoapi"
+)
+
+// fakeAPI is an in-memory todo server that can be taken down, or fail the
+// changes with err
+type fakeAPI struct {
+	items []item
+	down  bool
+	err   error
+}
+
+func (f *fakeAPI) getAll() ([]item, error) {
+	if f.down {
+		return nil, ErrConnection
+	}
+	if len(f.items) == 0 {
+		return nil, ErrNotFound
+	}
+	return append([]item{}, f.items...), nil
+}
+
+func (f *fakeAPI) getOne(id int) (item, error) {
+	if f.down {
+		return item{}, ErrConnection
+	}
+	if id < 1 || id > len(f.items) {
+		return item{}, ErrNotFound
+	}
+	return f.items[id-1], nil
+}
+
+func (f *fakeAPI) addItem(task string, due time.Time) error {
+	if f.down {
+		return ErrConnection
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.items = append(f.items, item{Task: task, CreatedAt: time.Now(), Due: due})
+	return nil
+}
+
+func (f *fakeAPI) completeItem(id int) error {
+	if f.down {
+		return ErrConnection
+	}
+	if f.err != nil {
+		return f.err
+	}
+	if id < 1 || id > len(f.items) {
+		return ErrNotFound
+	}
+	f.items[id-1].Done = true
+	return nil
+}
+
+func (f *fakeAPI) deleteItem(id int) error {
+	if f.down {
+		return ErrConnection
+	}
+	if f.err != nil {
+		return f.err
+	}
+	if id < 1 || id > len(f.items) {
+		return ErrNotFound
+	}
+	f.items = append(f.items[:id-1], f.items[id:]...)
+	return nil
+}
+
+func newFakeServer() *fakeAPI {
+	created := time.Date(2019, 10, 28, 8, 23, 38, 0, time.UTC)
+	f := &fakeAPI{}
+	for i := 1; i <= 3; i++ {
+		f.items = append(f.items, item{Task: fmt.Sprintf("Task %d", i), CreatedAt: created.Add(time.Duration(i) * time.Minute)})
+	}
+	return f
+}
+
+func TestOfflineCache(t *testing.T) {
+	server := newFakeServer()
+	var notice bytes.Buffer
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &notice)
+
+	var out bytes.Buffer
+	if err := listAction(&out, api, printer{}, false); err != nil {
+		t.Fatal(err)
+	}
+	online := out.String()
+
+	server.down = true
+	out.Reset()
+	if err := listAction(&out, api, printer{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != online {
+		t.Errorf("Expect cached output %q, got %q", online, out.String())
+	}
+	if !strings.HasPrefix(notice.String(), "stale:") {
+		t.Errorf("Expect stale marker, got %q", notice.String())
+	}
+
+	out.Reset()
+	if err := viewAction(&out, api, printer{}, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Task 2") {
+		t.Errorf("Expect cached item %q, got %q", "Task 2", out.String())
+	}
+}
+
+func TestOfflineQueue(t *testing.T) {
+	server := newFakeServer()
+	var notice bytes.Buffer
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &notice)
+
+	if _, err := api.getAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.down = true
+	var out bytes.Buffer
+	steps := []func() error{
+		func() error { return addAction(&out, api, printer{}, []string{"Offline", "task"}, "") },
+		func() error { return completeAction(&out, api, printer{}, "4") },
+		func() error { return completeAction(&out, api, printer{}, "3") },
+		func() error { return deleteAction(&out, api, printer{}, "1") },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c := strings.Count(notice.String(), "queued:"); c != 4 {
+		t.Fatalf("Expect 4 queued notices, got %d: %q", c, notice.String())
+	}
+
+	out.Reset()
+	if err := listAction(&out, api, printer{}, false); err != nil {
+		t.Fatal(err)
+	}
+	expOut := "-  1  Task 2      \nX  2  Task 3      \nX  3  Offline task\n"
+	if out.String() != expOut {
+		t.Errorf("Expect cached output with queued changes %q, got %q", expOut, out.String())
+	}
+
+	// Someone else deleted Task 1 and completed Task 3 while we were offline
+	server.down = false
+	server.items = server.items[1:]
+	server.items[1].Done = true
+
+	out.Reset()
+	if err := syncAction(&out, api, false); err != nil {
+		t.Fatal(err)
+	}
+	expSync := `sync: 2 change(s) replayed
+conflict: complete 3 "Task 3": item already completed on the server
+conflict: delete 1 "Task 1": item no longer exists on the server
+`
+	if out.String() != expSync {
+		t.Errorf("Expect sync report %q, got %q", expSync, out.String())
+	}
+
+	expItems := []struct {
+		task string
+		done bool
+	}{{"Task 2", false}, {"Task 3", true}, {"Offline task", true}}
+	if len(server.items) != len(expItems) {
+		t.Fatalf("Expect %d items on the server, got %d", len(expItems), len(server.items))
+	}
+	for k, exp := range expItems {
+		if server.items[k].Task != exp.task || server.items[k].Done != exp.done {
+			t.Errorf("Expect item %d to be %v, got %v", k+1, exp, server.items[k])
+		}
+	}
+
+	out.Reset()
+	if err := syncAction(&out, api, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Nothing to sync.\n" {
+		t.Errorf("Expect empty queue, got %q", out.String())
+	}
+}
+
+func TestOfflineReplayServerError(t *testing.T) {
+	server := newFakeServer()
+	var notice bytes.Buffer
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &notice)
+
+	if _, err := api.getAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.down = true
+	if err := api.completeItem(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.deleteItem(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server is back but fails, the changes must stay queued
+	server.down = false
+	server.err = &todoapi.APIError{StatusCode: http.StatusInternalServerError, Message: "boom"}
+
+	var out bytes.Buffer
+	err := syncAction(&out, api, false)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("Expect error %q, got %v", ErrInvalidResponse, err)
+	}
+	if !strings.Contains(out.String(), "sync: 2 change(s) still queued") {
+		t.Errorf("Expect the changes still queued, got %q", out.String())
+	}
+
+	// A refused change is a conflict and dropped
+	server.err = &todoapi.APIError{StatusCode: http.StatusBadRequest, Message: "bad"}
+	out.Reset()
+	if err := syncAction(&out, api, false); err != nil {
+		t.Fatal(err)
+	}
+	if c := strings.Count(out.String(), "conflict:"); c != 2 {
+		t.Errorf("Expect 2 conflicts, got %q", out.String())
+	}
+	ops, err := api.journal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("Expect empty queue, got %v", ops)
+	}
+}
+
+func TestOfflineAutoReplay(t *testing.T) {
+	server := newFakeServer()
+	var notice bytes.Buffer
+	api := newOfflineAPI(server, t.TempDir(), "grpc-localhost:9090", &notice)
+
+	server.down = true
+	if err := api.addItem("Queued", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still down, the queue is kept
+	if err := api.addItem("Queued again", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.down = false
+	items, err := api.getAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("Expect 5 items after replay, got %d", len(items))
+	}
+	if !strings.Contains(notice.String(), "sync: 2 change(s) replayed") {
+		t.Errorf("Expect replay notice, got %q", notice.String())
+	}
+
+	ops, err := api.journal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("Expect empty journal, got %v", ops)
+	}
+}
+
+func TestOfflineNoCache(t *testing.T) {
+	server := newFakeServer()
+	server.down = true
+	api := newOfflineAPI(server, t.TempDir(), "http://localhost:8080", &bytes.Buffer{})
+
+	if _, err := api.getAll(); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+	if err := api.completeItem(1); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputCSV      = "csv"
+	outputTemplate = "template"
+)
+
+// outputItem is an item as written by the machine-readable formats
+type outputItem struct {
+	ID          int        `json:"id" yaml:"id"`
+	Task        string     `json:"task" yaml:"task"`
+	Done        bool       `json:"done" yaml:"done"`
+	CreatedAt   time.Time  `json:"createdAt" yaml:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" yaml:"completedAt,omitempty"`
+	Due         *time.Time `json:"due,omitempty" yaml:"due,omitempty"`
+}
+
+func newOutputItem(id int, i item) outputItem {
+	o := outputItem{ID: id, Task: i.Task, Done: i.Done, CreatedAt: i.CreatedAt}
+	if i.Done {
+		o.CompletedAt = &i.CompletedAt
+	}
+	if !i.Due.IsZero() {
+		o.Due = &i.Due
+	}
+	return o
+}
+
+// printer writes the command results in the format selected by --output.
+// The zero value writes the human readable tables
+type printer struct {
+	format string
+	tmpl   *template.Template
+}
+
+// newPrinter parses an --output value, templates are given as template=<text>
+func newPrinter(output string) (printer, error) {
+	format, text, hasText := strings.Cut(output, "=")
+	switch format {
+	case "", outputTable, outputJSON, outputYAML, outputCSV:
+		if hasText {
+			return printer{}, fmt.Errorf("%w: output format %q takes no argument", ErrInvalid, format)
+		}
+		return printer{format: format}, nil
+	case outputTemplate:
+		if text == "" {
+			return printer{}, fmt.Errorf("%w: template output requires template=<text>", ErrInvalid)
+		}
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return printer{}, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		return printer{format: format, tmpl: tmpl}, nil
+	default:
+		return printer{}, fmt.Errorf("%w: unknown output format %q, use table, json, yaml, csv or template", ErrInvalid, format)
+	}
+}
+
+// table reports whether the human readable output is selected
+func (p printer) table() bool {
+	return p.format == "" || p.format == outputTable
+}
+
+// printItems writes a list of items. Templates are executed once with the whole list
+func (p printer) printItems(out io.Writer, items []outputItem) error {
+	switch p.format {
+	case outputJSON:
+		return writeJSONOutput(out, items)
+	case outputYAML:
+		return yaml.NewEncoder(out).Encode(items)
+	case outputCSV:
+		return writeCSV(out, items)
+	case outputTemplate:
+		return p.tmpl.Execute(out, items)
+	}
+	return fmt.Errorf("%w: no table output for this command", ErrInvalid)
+}
+
+// printItem writes a single item
+func (p printer) printItem(out io.Writer, i outputItem) error {
+	switch p.format {
+	case outputJSON:
+		return writeJSONOutput(out, i)
+	case outputYAML:
+		return yaml.NewEncoder(out).Encode(i)
+	case outputCSV:
+		return writeCSV(out, []outputItem{i})
+	case outputTemplate:
+		return p.tmpl.Execute(out, i)
+	}
+	return fmt.Errorf("%w: no table output for this command", ErrInvalid)
+}
+
+func writeJSONOutput(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(out io.Writer, items []outputItem) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"id", "task", "done", "created_at", "completed_at", "due"}); err != nil {
+		return err
+	}
+	for _, i := range items {
+		completedAt, due := "", ""
+		if i.CompletedAt != nil {
+			completedAt = i.CompletedAt.Format(time.RFC3339)
+		}
+		if i.Due != nil {
+			due = i.Due.Format(time.RFC3339)
+		}
+		record := []string{
+			strconv.Itoa(i.ID),
+			i.Task,
+			strconv.FormatBool(i.Done),
+			i.CreatedAt.Format(time.RFC3339),
+			completedAt,
+			due,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPrinter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+		expErr error
+	}{
+		{name: "Default", output: ""},
+		{name: "Table", output: "table"},
+		{name: "JSON", output: "json"},
+		{name: "Template", output: "template={{.Task}}"},
+		{name: "TemplateMissing", output: "template", expErr: ErrInvalid},
+		{name: "TemplateInvalid", output: "template={{.Task", expErr: ErrInvalid},
+		{name: "Argument", output: "json=x", expErr: ErrInvalid},
+		{name: "Unknown", output: "xml", expErr: ErrInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newPrinter(tc.output)
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expect error %q, got %q", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestListOutput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		isActive bool
+		expOut   string
+	}{
+		{name: "JSON", output: "json", isActive: true,
+			expOut: `[
+  {
+    "id": 3,
+    "task": "Task 3",
+    "done": false,
+    "createdAt": "2019-10-28T08:26:38Z",
+    "due": "2019-10-30T23:59:59Z"
+  }
+]
+`},
+		{name: "YAML", output: "yaml", isActive: true,
+			expOut: `- id: 3
+  task: Task 3
+  done: false
+  createdAt: 2019-10-28T08:26:38Z
+  due: 2019-10-30T23:59:59Z
+`},
+		{name: "CSV", output: "csv",
+			expOut: `id,task,done,created_at,completed_at,due
+1,Task 1,true,2019-10-28T08:24:38Z,2019-10-28T09:00:00Z,
+2,Task 2,true,2019-10-28T08:25:38Z,2019-10-28T09:00:00Z,
+3,Task 3,false,2019-10-28T08:26:38Z,,2019-10-30T23:59:59Z
+`},
+		{name: "Template", output: "template={{range .}}{{.ID}}:{{.Task}}\n{{end}}",
+			expOut: "1:Task 1\n2:Task 2\n3:Task 3\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeServer()
+			for k := range server.items[:2] {
+				server.items[k].Done = true
+				server.items[k].CompletedAt = server.items[k].CreatedAt.Truncate(time.Hour).Add(time.Hour)
+			}
+			server.items[2].Due = time.Date(2019, 10, 30, 23, 59, 59, 0, time.UTC)
+
+			p, err := newPrinter(tc.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := listAction(&out, server, p, tc.isActive); err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+			if out.String() != tc.expOut {
+				t.Errorf("Expect output %q, got %q", tc.expOut, out.String())
+			}
+		})
+	}
+}
+
+func TestMutationOutput(t *testing.T) {
+	p, err := newPrinter("template={{.ID}} {{.Task}} {{.Done}}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		action func(out *bytes.Buffer, api todoAPI) error
+		expOut string
+	}{
+		{name: "Add", expOut: "4 New task false\n",
+			action: func(out *bytes.Buffer, api todoAPI) error {
+				return addAction(out, api, p, []string{"New", "task"}, "")
+			}},
+		{name: "View", expOut: "2 Task 2 false\n",
+			action: func(out *bytes.Buffer, api todoAPI) error { return viewAction(out, api, p, "2") }},
+		{name: "Complete", expOut: "2 Task 2 true\n",
+			action: func(out *bytes.Buffer, api todoAPI) error { return completeAction(out, api, p, "2") }},
+		{name: "Delete", expOut: "2 Task 2 false\n",
+			action: func(out *bytes.Buffer, api todoAPI) error { return deleteAction(out, api, p, "2") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tc.action(&out, newFakeServer()); err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+			if out.String() != tc.expOut {
+				t.Errorf("Expect output %q, got %q", tc.expOut, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"apis/todoClient/todoapi"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// remindCmd represents the remind command
+var remindCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Alert when tasks become due",
+	Long: `Poll the server for the tasks due today or overdue and print an alert,
+ringing the terminal bell, the first time each one shows up.
+
+With --hook, the command is also run by the shell for each alert with the
+environment variables TODO_ID, TODO_TASK, TODO_DUE (RFC 3339) and TODO_STATUS
+(today or overdue) set, for instance to show a desktop notification:
+
+  todoClient remind --hook 'notify-send "$TODO_TASK" "$TODO_STATUS"'
+
+Only the http transport serves the due summary, the gRPC service has none.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("%w: interval must be positive", ErrInvalid)
+		}
+		once, err := cmd.Flags().GetBool("once")
+		if err != nil {
+			return err
+		}
+
+		if t := viper.GetString("transport"); t != "" && t != "http" {
+			return fmt.Errorf("%w: remind needs the http transport, the gRPC service serves no due summary", ErrInvalid)
+		}
+		cacheDir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+		client, err := newRESTClient(cacheDir, viper.GetDuration("timeout"), viper.GetInt("retries"))
+		if err != nil {
+			return err
+		}
+
+		r := &reminder{
+			due:    client.Due,
+			hook:   viper.GetString("remind-hook"),
+			run:    runHook,
+			out:    os.Stdout,
+			errOut: os.Stderr,
+		}
+		if once {
+			return r.poll(cmd.Context())
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		return r.watch(ctx, interval)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(remindCmd)
+	remindCmd.Flags().Duration("interval", time.Minute, "Interval between polls of the server")
+	remindCmd.Flags().Bool("once", false, "Poll once and exit")
+	remindCmd.Flags().String("hook", "", "Shell command run for each alert")
+	viper.BindPFlag("remind-hook", remindCmd.Flags().Lookup("hook"))
+}
+
+// Due statuses raising an alert
+const (
+	statusToday   = "today"
+	statusOverdue = "overdue"
+)
+
+// reminder alerts once per task and status. Tasks are keyed by name and due
+// date since the IDs change when an item before them is deleted
+type reminder struct {
+	due    func(ctx context.Context) (todoapi.DueSummary, error)
+	hook   string
+	run    func(ctx context.Context, command string, env []string) error
+	out    io.Writer
+	errOut io.Writer
+	seen   map[string]string
+}
+
+// watch polls until ctx is done, reporting the poll errors without stopping
+func (r *reminder) watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(r.errOut, "remind: %s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the due summary and alerts for the tasks newly due
+func (r *reminder) poll(ctx context.Context) error {
+	s, err := r.due(ctx)
+	if err != nil {
+		return err
+	}
+
+	if r.seen == nil {
+		r.seen = map[string]string{}
+	}
+	current := map[string]string{}
+	alert := func(status string, items []todoapi.DueItem) error {
+		for _, i := range items {
+			key := i.Task + "\x00" + i.Due.Format(time.RFC3339)
+			current[key] = status
+			if r.seen[key] == status {
+				continue
+			}
+			if err := r.alert(ctx, status, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := alert(statusOverdue, s.Overdue); err != nil {
+		return err
+	}
+	if err := alert(statusToday, s.Today); err != nil {
+		return err
+	}
+
+	// Forget the tasks completed or deleted so a new one with the same name alerts again
+	r.seen = current
+	return nil
+}
+
+func (r *reminder) alert(ctx context.Context, status string, i todoapi.DueItem) error {
+	msg := "due today"
+	if status == statusOverdue {
+		msg = "overdue"
+	}
+	if _, err := fmt.Fprintf(r.out, "\a%d: %s is %s (due %s)\n", i.ID, i.Task, msg, i.Due.Local().Format("Mon Jan 02 15:04")); err != nil {
+		return err
+	}
+
+	if r.hook == "" {
+		return nil
+	}
+	env := []string{
+		"TODO_ID=" + strconv.Itoa(i.ID),
+		"TODO_TASK=" + i.Task,
+		"TODO_DUE=" + i.Due.Format(time.RFC3339),
+		"TODO_STATUS=" + status,
+	}
+	if err := r.run(ctx, r.hook, env); err != nil {
+		// A failing hook shouldn't stop the reminders
+		fmt.Fprintf(r.errOut, "remind: hook: %s\n", err)
+	}
+	return nil
+}
+
+// runHook runs command with the shell, adding env to the environment
+func runHook(ctx context.Context, command string, env []string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"apis/todoClient/todoapi"
+)
+
+func TestReminder(t *testing.T) {
+	due := time.Date(2024, 3, 1, 17, 0, 0, 0, time.Local)
+	dueItem := func(id int, task string) todoapi.DueItem {
+		return todoapi.DueItem{ID: id, Item: todoapi.Item{Task: task, Due: due}}
+	}
+
+	var summary todoapi.DueSummary
+	var hooks []string
+	var out, errOut bytes.Buffer
+	r := &reminder{
+		due: func(ctx context.Context) (todoapi.DueSummary, error) {
+			return summary, nil
+		},
+		hook: "notify",
+		run: func(ctx context.Context, command string, env []string) error {
+			hooks = append(hooks, command+" "+strings.Join(env, " "))
+			return errors.New("hook failed")
+		},
+		out:    &out,
+		errOut: &errOut,
+	}
+
+	testCases := []struct {
+		name     string
+		summary  todoapi.DueSummary
+		expOut   string
+		expHooks []string
+	}{
+		{
+			name:    "DueToday",
+			summary: todoapi.DueSummary{Today: []todoapi.DueItem{dueItem(1, "Task 1")}, Week: []todoapi.DueItem{dueItem(2, "Task 2")}},
+			expOut:  "\a1: Task 1 is due today (due Fri Mar 01 17:00)\n",
+			expHooks: []string{
+				"notify TODO_ID=1 TODO_TASK=Task 1 TODO_DUE=" + due.Format(time.RFC3339) + " TODO_STATUS=today",
+			},
+		},
+		{
+			name:    "StillDueToday",
+			summary: todoapi.DueSummary{Today: []todoapi.DueItem{dueItem(1, "Task 1")}},
+		},
+		{
+			name:    "Overdue",
+			summary: todoapi.DueSummary{Overdue: []todoapi.DueItem{dueItem(1, "Task 1")}},
+			expOut:  "\a1: Task 1 is overdue (due Fri Mar 01 17:00)\n",
+			expHooks: []string{
+				"notify TODO_ID=1 TODO_TASK=Task 1 TODO_DUE=" + due.Format(time.RFC3339) + " TODO_STATUS=overdue",
+			},
+		},
+		{
+			name:    "IDChanged",
+			summary: todoapi.DueSummary{Overdue: []todoapi.DueItem{dueItem(3, "Task 1")}},
+		},
+		{
+			name: "Completed",
+		},
+		{
+			name:    "AddedAgain",
+			summary: todoapi.DueSummary{Overdue: []todoapi.DueItem{dueItem(2, "Task 1")}},
+			expOut:  "\a2: Task 1 is overdue (due Fri Mar 01 17:00)\n",
+			expHooks: []string{
+				"notify TODO_ID=2 TODO_TASK=Task 1 TODO_DUE=" + due.Format(time.RFC3339) + " TODO_STATUS=overdue",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			summary = tc.summary
+			hooks = nil
+			out.Reset()
+			errOut.Reset()
+
+			if err := r.poll(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expOut {
+				t.Errorf("Expect %q, got %q", tc.expOut, out.String())
+			}
+			if strings.Join(hooks, "\n") != strings.Join(tc.expHooks, "\n") {
+				t.Errorf("Expect hooks %q, got %q", tc.expHooks, hooks)
+			}
+			if len(tc.expHooks) > 0 && !strings.Contains(errOut.String(), "hook failed") {
+				t.Errorf("Expect hook error to be reported, got %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestReminderWatch(t *testing.T) {
+	var errOut bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	polls := 0
+	r := &reminder{
+		due: func(context.Context) (todoapi.DueSummary, error) {
+			polls++
+			if polls == 2 {
+				cancel()
+			}
+			return todoapi.DueSummary{}, ErrConnection
+		},
+		out:    &bytes.Buffer{},
+		errOut: &errOut,
+	}
+
+	if err := r.watch(ctx, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Errorf("Expect 2 polls, got %d", polls)
+	}
+	if !strings.Contains(errOut.String(), "remind: "+ErrConnection.Error()) {
+		t.Errorf("Expect poll error to be reported, got %q", errOut.String())
+	}
+}
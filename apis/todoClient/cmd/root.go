@@ -60,12 +60,25 @@ func init() {
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	rootCmd.PersistentFlags().String("api-root", "http://localhost:8080", "Todo API URL")
+	rootCmd.PersistentFlags().String("transport", "http", "Transport used to reach the API: http or grpc")
+	rootCmd.PersistentFlags().String("grpc-addr", "localhost:9090", "Todo gRPC API address")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Directory for the offline cache and queue (default is the user cache directory)")
+	rootCmd.PersistentFlags().Duration("timeout", clientTimeout, "Timeout of each request to the API")
+	rootCmd.PersistentFlags().Int("retries", 3, "Number of times a failed request is retried")
+	rootCmd.PersistentFlags().String("context", "", "Context from the config file to use (default is the current context)")
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable, "Output format: table, json, yaml, csv or template=<Go template>")
+	err := rootCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeContexts(cmd, nil, toComplete)
+	})
+	cobra.CheckErr(err)
 	replacer := strings.NewReplacer("-", "_")
 	viper.SetEnvKeyReplacer(replacer)
 	viper.SetEnvPrefix("TODO")
 
-	if err := viper.BindPFlag("api-root", rootCmd.PersistentFlags().Lookup("api-root")); err != nil {
-		fmt.Fprintf(os.Stdout, "%v: Error when binding api-root flag to viper", err)
+	for _, key := range []string{"api-root", "transport", "grpc-addr", "cache-dir", "timeout", "retries", "output", "context"} {
+		if err := viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(key)); err != nil {
+			fmt.Fprintf(os.Stdout, "%v: Error when binding %s flag to viper", err, key)
+		}
 	}
 }
 
@@ -0,0 +1,112 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Send the changes queued while the server was unreachable",
+	Long: `Send the changes queued while the server was unreachable.
+
+With --file, reconcile instead the list of the todo CLI stored in the given
+file with the server list: items added, completed or deleted on either side
+since the last sync are applied to the other one.`,
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		if file == "" {
+			return syncAction(os.Stdout, api, dryRun)
+		}
+
+		stateFile, err := api.fileSyncStateFile(file)
+		if err != nil {
+			return err
+		}
+		// The queued changes go first, the file sync needs the server anyway,
+		// reported as some may conflict
+		ops, err := api.journal()
+		if err != nil {
+			return err
+		}
+		if len(ops) > 0 {
+			if err := syncAction(os.Stdout, api, dryRun); err != nil {
+				return err
+			}
+		}
+		return fileSyncAction(os.Stdout, api.api, file, stateFile, dryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("file", "", "todo CLI file to reconcile with the server list")
+	syncCmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+}
+
+func syncAction(out io.Writer, api *offlineAPI, dryRun bool) error {
+	ops, err := api.journal()
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		_, err := fmt.Fprintln(out, "Nothing to sync.")
+		return err
+	}
+
+	if dryRun {
+		for _, op := range ops {
+			if _, err := fmt.Fprintf(out, "queued: %s\n", op); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	report, err := api.replay()
+	if perr := printReport(out, report); perr != nil {
+		return perr
+	}
+	return err
+}
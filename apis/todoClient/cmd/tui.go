@@ -0,0 +1,255 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Manage the list in a full screen terminal UI",
+	Long: `Show the list in a full screen terminal UI refreshed periodically.
+
+Keys:
+  up/down, k/j  select an item
+  a             add a task, Enter to save it and Esc to cancel
+  c             complete the selected item
+  d             delete the selected item
+  r             refresh the list
+  q             quit`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, err := cmd.Flags().GetDuration("refresh")
+		if err != nil {
+			return err
+		}
+		if refresh <= 0 {
+			return fmt.Errorf("%w: refresh interval must be positive", ErrInvalid)
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		a, err := newTUIApp(api, refresh)
+		if err != nil {
+			return err
+		}
+		return a.run()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().Duration("refresh", 5*time.Second, "Interval between list refreshes")
+}
+
+type tuiAction int
+
+const (
+	tuiNone tuiAction = iota
+	tuiQuit
+	tuiRefresh
+	tuiAdd
+	tuiComplete
+	tuiDelete
+)
+
+// tuiOp is an operation requested from the keyboard
+type tuiOp struct {
+	action tuiAction
+	id     int
+	task   string
+}
+
+// tuiModel is the state of the terminal UI, shared by the keyboard handler
+// and the goroutine talking to the API
+type tuiModel struct {
+	mu       sync.Mutex
+	items    []item
+	selected int
+	adding   bool
+	input    []rune
+	status   string
+}
+
+// handleKey updates the selection and the task being typed, returning the
+// operation to send to the API, if any
+func (m *tuiModel) handleKey(k keyboard.Key) tuiOp {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.adding {
+		switch k {
+		case keyboard.KeyEnter:
+			task := strings.TrimSpace(string(m.input))
+			m.adding, m.input = false, nil
+			if task == "" {
+				return tuiOp{}
+			}
+			return tuiOp{action: tuiAdd, task: task}
+		case keyboard.KeyEsc:
+			m.adding, m.input = false, nil
+		case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+			if len(m.input) > 0 {
+				m.input = m.input[:len(m.input)-1]
+			}
+		default:
+			// Special keys are negative
+			if k >= ' ' {
+				m.input = append(m.input, rune(k))
+			}
+		}
+		return tuiOp{}
+	}
+
+	switch k {
+	case 'q', 'Q':
+		return tuiOp{action: tuiQuit}
+	case keyboard.KeyArrowUp, 'k':
+		if m.selected > 0 {
+			m.selected--
+		}
+	case keyboard.KeyArrowDown, 'j':
+		if m.selected < len(m.items)-1 {
+			m.selected++
+		}
+	case 'a':
+		m.adding = true
+	case 'r':
+		return tuiOp{action: tuiRefresh}
+	case 'c':
+		if len(m.items) > 0 {
+			return tuiOp{action: tuiComplete, id: m.selected + 1}
+		}
+	case 'd':
+		if len(m.items) > 0 {
+			return tuiOp{action: tuiDelete, id: m.selected + 1}
+		}
+	}
+	return tuiOp{}
+}
+
+// apply sends the operation to the API and reloads the list
+func (m *tuiModel) apply(api todoAPI, op tuiOp) {
+	var (
+		err error
+		msg string
+	)
+	switch op.action {
+	case tuiAdd:
+		err = api.addItem(op.task, time.Time{})
+		msg = fmt.Sprintf("Added task %q.", op.task)
+	case tuiComplete:
+		err = api.completeItem(op.id)
+		msg = fmt.Sprintf("Item number %d marked as completed.", op.id)
+	case tuiDelete:
+		err = api.deleteItem(op.id)
+		msg = fmt.Sprintf("Item number %d deleted.", op.id)
+	}
+	if err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+
+	items, err := api.getAll()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		m.setStatus(err.Error())
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = items
+	m.selected = max(0, min(m.selected, len(items)-1))
+	if msg != "" {
+		m.status = msg
+	}
+}
+
+func (m *tuiModel) setStatus(s string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = s
+}
+
+// Write shows the notices of the offline API in the status line
+func (m *tuiModel) Write(p []byte) (int, error) {
+	lines := strings.Split(strings.TrimSpace(string(p)), "\n")
+	m.setStatus(lines[len(lines)-1])
+	return len(p), nil
+}
+
+// listLines returns at most height lines of the list, scrolled to show the
+// selected item, and the index of the selected line
+func (m *tuiModel) listLines(height int) ([]string, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.items) == 0 {
+		return []string{"No items, press a to add one."}, -1
+	}
+
+	first := 0
+	if height > 0 && m.selected >= height {
+		first = m.selected - height + 1
+	}
+	last := len(m.items)
+	if height > 0 {
+		last = min(last, first+height)
+	}
+
+	lines := make([]string, 0, last-first)
+	for k := first; k < last; k++ {
+		done := "-"
+		if m.items[k].Done {
+			done = "X"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %3d  %s", done, k+1, m.items[k].Task))
+	}
+	return lines, m.selected - first
+}
+
+// inputLine returns the task being typed, empty when not adding
+func (m *tuiModel) inputLine() (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return string(m.input), m.adding
+}
+
+func (m *tuiModel) statusLine() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mum4k/termdash/keyboard"
+)
+
+func TestTUIKeys(t *testing.T) {
+	server := newFakeServer()
+	m := &tuiModel{}
+	m.apply(server, tuiOp{action: tuiRefresh})
+
+	testCases := []struct {
+		name        string
+		keys        []keyboard.Key
+		expOp       tuiOp
+		expSelected int
+	}{
+		{name: "Quit", keys: []keyboard.Key{'q'}, expOp: tuiOp{action: tuiQuit}},
+		{name: "Navigate", keys: []keyboard.Key{keyboard.KeyArrowDown, 'j', 'j', 'k'}, expSelected: 1},
+		{name: "StayOnTop", keys: []keyboard.Key{keyboard.KeyArrowUp}, expSelected: 0},
+		{name: "Complete", keys: []keyboard.Key{'j', 'c'}, expOp: tuiOp{action: tuiComplete, id: 2}, expSelected: 1},
+		{name: "Delete", keys: []keyboard.Key{'j', 'j', 'd'}, expOp: tuiOp{action: tuiDelete, id: 3}, expSelected: 2},
+		{name: "Add", keys: []keyboard.Key{'a', 'N', 'e', 'w', 'x', keyboard.KeyBackspace2, ' ', 'q', keyboard.KeyEnter},
+			expOp: tuiOp{action: tuiAdd, task: "New q"}},
+		{name: "AddCancelled", keys: []keyboard.Key{'a', 'x', keyboard.KeyEsc, 'd'}, expOp: tuiOp{action: tuiDelete, id: 1}},
+		{name: "AddBlank", keys: []keyboard.Key{'a', ' ', keyboard.KeyEnter}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m.selected = 0
+
+			var op tuiOp
+			for _, k := range tc.keys {
+				op = m.handleKey(k)
+			}
+
+			if op != tc.expOp {
+				t.Errorf("Expect operation %v, got %v", tc.expOp, op)
+			}
+			if m.selected != tc.expSelected {
+				t.Errorf("Expect selected item %d, got %d", tc.expSelected, m.selected)
+			}
+		})
+	}
+}
+
+func TestTUIApply(t *testing.T) {
+	server := newFakeServer()
+	m := &tuiModel{}
+	m.apply(server, tuiOp{action: tuiRefresh})
+
+	m.selected = 2
+	m.apply(server, tuiOp{action: tuiDelete, id: 3})
+	if m.selected != 1 {
+		t.Errorf("Expect selection to move to the last item, got %d", m.selected)
+	}
+	if m.status != "Item number 3 deleted." {
+		t.Errorf("Expect status %q, got %q", "Item number 3 deleted.", m.status)
+	}
+
+	m.apply(server, tuiOp{action: tuiComplete, id: 1})
+	m.apply(server, tuiOp{action: tuiAdd, task: "New task"})
+	lines, selected := m.listLines(0)
+	exp := "X    1  Task 1|-    2  Task 2|-    3  New task"
+	if strings.Join(lines, "|") != exp {
+		t.Errorf("Expect %q, got %q", exp, strings.Join(lines, "|"))
+	}
+	if selected != 1 {
+		t.Errorf("Expect selected line 1, got %d", selected)
+	}
+
+	server.down = true
+	m.apply(server, tuiOp{action: tuiRefresh})
+	if m.status != ErrConnection.Error() {
+		t.Errorf("Expect status %q, got %q", ErrConnection, m.status)
+	}
+	if len(m.items) != 3 {
+		t.Errorf("Expect items to be kept on error, got %d", len(m.items))
+	}
+}
+
+func TestTUIListScroll(t *testing.T) {
+	m := &tuiModel{}
+	for range 10 {
+		m.items = append(m.items, item{Task: "Task"})
+	}
+	m.selected = 7
+
+	lines, selected := m.listLines(3)
+	if len(lines) != 3 || !strings.Contains(lines[2], " 8 ") || selected != 2 {
+		t.Errorf("Expect lines 6 to 8 with the last selected, got %q, %d", lines, selected)
+	}
+
+	m.items = nil
+	if lines, selected := m.listLines(3); len(lines) != 1 || selected != -1 {
+		t.Errorf("Expect empty list message, got %q, %d", lines, selected)
+	}
+}
+
+// slowAPI holds the adds until release is closed
+type slowAPI struct {
+	todoAPI
+	release chan struct{}
+}
+
+func (a slowAPI) addItem(task string, due time.Time) error {
+	<-a.release
+	return a.todoAPI.addItem(task, due)
+}
+
+func TestTUISend(t *testing.T) {
+	api := slowAPI{todoAPI: newFakeServer(), release: make(chan struct{})}
+	a := &tuiApp{model: &tuiModel{}, api: api, redrawCh: make(chan bool, 1), wake: make(chan struct{}, 1)}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	defer a.cancel()
+	go a.worker()
+
+	// The keys keep coming while the first add waits for the server
+	sent := make(chan struct{})
+	go func() {
+		a.send(tuiOp{action: tuiAdd, task: "Task 4"})
+		a.send(tuiOp{action: tuiAdd, task: "Task 5"})
+		a.send(tuiOp{action: tuiRefresh})
+		a.send(tuiOp{action: tuiRefresh})
+		a.send(tuiOp{action: tuiDelete, id: 1})
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Expect send not to block while a request runs")
+	}
+	close(api.release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		a.mu.Lock()
+		n := len(a.queue)
+		a.mu.Unlock()
+		if lines, _ := a.model.listLines(0); n == 0 && strings.Join(lines, "|") == "-    1  Task 2|-    2  Task 3|-    3  Task 4|-    4  Task 5" {
+			break
+		}
+		if time.Now().After(deadline) {
+			lines, _ := a.model.listLines(0)
+			t.Fatalf("Expect all the operations applied, got %q", lines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
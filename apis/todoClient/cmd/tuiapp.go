@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/container/grid"
+	"github.com/mum4k/termdash/linestyle"
+	"github.com/mum4k/termdash/terminal/tcell"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgets/text"
+)
+
+// listHeightPerc is the share of the terminal height used by the list
+const listHeightPerc = 80
+
+// tuiApp renders a tuiModel with termdash, running the API operations in
+// the background so the UI stays responsive
+type tuiApp struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	controller *termdash.Controller
+	terminal   *tcell.Terminal
+	model      *tuiModel
+	api        todoAPI
+	refresh    time.Duration
+	redrawCh   chan bool
+	size       image.Point
+
+	// queue holds the operations waiting for the worker, woken through wake
+	mu    sync.Mutex
+	queue []tuiOp
+	wake  chan struct{}
+
+	txtList   *text.Text
+	txtInput  *text.Text
+	txtStatus *text.Text
+}
+
+func newTUIApp(api *offlineAPI, refresh time.Duration) (*tuiApp, error) {
+	a := &tuiApp{
+		model:    &tuiModel{status: "Loading..."},
+		api:      api,
+		refresh:  refresh,
+		redrawCh: make(chan bool, 1),
+		wake:     make(chan struct{}, 1),
+	}
+	// Notices would be written over the UI
+	api.notice = a.model
+
+	var err error
+	if a.txtList, err = text.New(text.DisableScrolling()); err != nil {
+		return nil, err
+	}
+	if a.txtInput, err = text.New(text.DisableScrolling()); err != nil {
+		return nil, err
+	}
+	if a.txtStatus, err = text.New(text.DisableScrolling()); err != nil {
+		return nil, err
+	}
+
+	builder := grid.New()
+	builder.Add(
+		grid.RowHeightPerc(listHeightPerc, grid.Widget(a.txtList,
+			container.Border(linestyle.Light),
+			container.BorderTitle("Todos - a:add c:complete d:delete r:refresh q:quit"),
+		)),
+		grid.RowHeightPerc(10, grid.Widget(a.txtInput,
+			container.Border(linestyle.Light),
+			container.BorderTitle("New task"),
+		)),
+		grid.RowHeightPerc(9, grid.Widget(a.txtStatus)),
+	)
+	opts, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.terminal, err = tcell.New(); err != nil {
+		return nil, err
+	}
+	c, err := container.New(a.terminal, opts...)
+	if err != nil {
+		a.terminal.Close()
+		return nil, err
+	}
+
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	keys := func(k *terminalapi.Keyboard) {
+		op := a.model.handleKey(k.Key)
+		switch op.action {
+		case tuiQuit:
+			a.cancel()
+		case tuiNone:
+			a.requestRedraw()
+		default:
+			a.send(op)
+		}
+	}
+	if a.controller, err = termdash.NewController(a.terminal, c, termdash.KeyboardSubscriber(keys)); err != nil {
+		a.cancel()
+		a.terminal.Close()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// send queues an operation without blocking, so the keys are handled while
+// a slow request runs, dropping refreshes while another one is pending
+func (a *tuiApp) send(op tuiOp) {
+	a.mu.Lock()
+	isRefresh := func(o tuiOp) bool { return o.action == tuiRefresh }
+	if !isRefresh(op) || !slices.ContainsFunc(a.queue, isRefresh) {
+		a.queue = append(a.queue, op)
+	}
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the oldest operation queued
+func (a *tuiApp) next() (tuiOp, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.queue) == 0 {
+		return tuiOp{}, false
+	}
+	op := a.queue[0]
+	a.queue = a.queue[1:]
+	return op, true
+}
+
+func (a *tuiApp) requestRedraw() {
+	select {
+	case a.redrawCh <- true:
+	default:
+	}
+}
+
+// worker applies the operations one at a time
+func (a *tuiApp) worker() {
+	for {
+		select {
+		case <-a.wake:
+			for op, ok := a.next(); ok && a.ctx.Err() == nil; op, ok = a.next() {
+				a.model.apply(a.api, op)
+				a.requestRedraw()
+			}
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *tuiApp) draw() error {
+	height := a.terminal.Size().Y*listHeightPerc/100 - 2
+
+	a.txtList.Reset()
+	lines, selected := a.model.listLines(height)
+	for k, l := range lines {
+		var opts []text.WriteOption
+		if k == selected {
+			opts = append(opts, text.WriteCellOpts(cell.Inverse()))
+		}
+		if err := a.txtList.Write(l+"\n", opts...); err != nil {
+			return err
+		}
+	}
+
+	a.txtInput.Reset()
+	if input, adding := a.model.inputLine(); adding {
+		if err := a.txtInput.Write("> " + input + "_"); err != nil {
+			return err
+		}
+	}
+
+	a.txtStatus.Reset()
+	if status := a.model.statusLine(); status != "" {
+		return a.txtStatus.Write(status, text.WriteCellOpts(cell.FgColor(cell.ColorYellow)))
+	}
+	return nil
+}
+
+func (a *tuiApp) resize() error {
+	if a.size.Eq(a.terminal.Size()) {
+		return nil
+	}
+
+	a.size = a.terminal.Size()
+	if err := a.terminal.Clear(); err != nil {
+		return err
+	}
+
+	a.requestRedraw()
+	return nil
+}
+
+func (a *tuiApp) run() error {
+	defer a.terminal.Close()
+	defer a.controller.Close()
+	defer a.cancel()
+
+	go a.worker()
+	a.send(tuiOp{action: tuiRefresh})
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	refresh := time.NewTicker(a.refresh)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.resize(); err != nil {
+				return err
+			}
+		case <-refresh.C:
+			a.send(tuiOp{action: tuiRefresh})
+		case <-a.redrawCh:
+			if err := a.draw(); err != nil {
+				return err
+			}
+			if err := a.controller.Redraw(); err != nil {
+				return fmt.Errorf("termdash.controller.Redraw => %v", err)
+			}
+		case <-a.ctx.Done():
+			return nil
+		}
+	}
+}
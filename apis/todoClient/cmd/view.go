@@ -39,8 +39,18 @@ var viewCmd = &cobra.Command{
 	SilenceUsage: true,
 	Args:         cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiRoot := viper.GetString("api-root")
-		return viewAction(os.Stdout, apiRoot, args[0])
+		p, err := newPrinter(viper.GetString("output"))
+		if err != nil {
+			return err
+		}
+
+		api, closeAPI, err := newAPI()
+		if err != nil {
+			return err
+		}
+		defer closeAPI()
+
+		return viewAction(os.Stdout, api, p, args[0])
 	},
 }
 
@@ -48,24 +58,30 @@ func init() {
 	rootCmd.AddCommand(viewCmd)
 }
 
-func viewAction(out io.Writer, apiRoot string, arg string) error {
+func viewAction(out io.Writer, api todoAPI, p printer, arg string) error {
 	id, err := strconv.Atoi(arg)
 	if err != nil {
 		return fmt.Errorf("%w: Item id must be a number", ErrNotNumber)
 	}
 
-	item, err := getOne(apiRoot, id)
+	item, err := api.getOne(id)
 	if err != nil {
 		return err
 	}
 
-	return printOne(out, item)
+	if p.table() {
+		return printOne(out, item)
+	}
+	return p.printItem(out, newOutputItem(id, item))
 }
 
 func printOne(out io.Writer, item item) error {
 	w := tabwriter.NewWriter(out, 14, 2, 0, ' ', 0)
 	fmt.Fprintf(w, "Task:\t%s\n", item.Task)
 	fmt.Fprintf(w, "Created at:\t%s\n", item.CreatedAt.Format(timeFormat))
+	if !item.Due.IsZero() {
+		fmt.Fprintf(w, "Due:\t%s\n", item.Due.Format(timeFormat))
+	}
 	if item.Done {
 		fmt.Fprintf(w, "Completed: \t%s\n", "Yes")
 		fmt.Fprintf(w, "Completed At: \t%s\n", item.CompletedAt.Format(timeFormat))
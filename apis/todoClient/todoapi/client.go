@@ -0,0 +1,240 @@
+// Package todoapi is a client for the REST API served by todoServer.
+//
+// Items are identified by their position in the list, starting at 1, so the
+// ID of an item changes when an item before it is deleted.
+package todoapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrConnection is returned when the server can't be reached
+	ErrConnection = errors.New("Connection Error")
+	// ErrNotFound is returned when the item or the list doesn't exist
+	ErrNotFound = errors.New("Not Found")
+	// ErrInvalidResponse is returned when the server replies with an unexpected status or body
+	ErrInvalidResponse = errors.New("Invalid Server Response")
+	// ErrInvalid is returned for invalid data, either sent or received
+	ErrInvalid = errors.New("Invalid Data")
+)
+
+// DefaultTimeout is the timeout of the HTTP client used when none is configured
+const DefaultTimeout = 10 * time.Second
+
+// Item is a todo item
+type Item struct {
+	Task        string
+	Done        bool
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	// Due is the optional deadline of the item, zero when none
+	Due time.Time `json:",omitzero"`
+}
+
+// DueItem is a pending item with a due date and its ID
+type DueItem struct {
+	ID int
+	Item
+}
+
+// DueSummary groups the pending items by due date
+type DueSummary struct {
+	Overdue []DueItem `json:"overdue"`
+	Today   []DueItem `json:"today"`
+	// Week holds the items due in the 7 days after today
+	Week []DueItem `json:"week"`
+}
+
+type todoResponse struct {
+	Results      []Item `json:"results"`
+	Date         int64  `json:"date"`
+	TotalResults int    `json:"totalResults"`
+}
+
+// APIError is returned when the server replies with an unexpected status.
+// It matches ErrNotFound or ErrInvalidResponse with errors.Is
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Unwrap(), e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return ErrInvalidResponse
+}
+
+// Client talks to the todo API rooted at BaseURL. The zero values of the
+// optional fields send each request once with a default HTTP client
+type Client struct {
+	BaseURL string
+
+	// HTTPClient sends the requests, a client with DefaultTimeout when nil
+	HTTPClient *http.Client
+	// Token is sent as a bearer token when set
+	Token string
+	// Retry controls how transient failures are retried
+	Retry RetryPolicy
+	// Breaker stops sending requests to a failing server when set
+	Breaker Breaker
+}
+
+// NewClient returns a client for the API rooted at baseURL, like http://localhost:8080
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// List returns all the items, or an error matching ErrNotFound when the list is empty
+func (c *Client) List(ctx context.Context) ([]Item, error) {
+	return c.getItems(ctx, fmt.Sprintf("%s/todo", c.BaseURL))
+}
+
+// Get returns the item with the given ID
+func (c *Client) Get(ctx context.Context, id int) (Item, error) {
+	items, err := c.getItems(ctx, fmt.Sprintf("%s/todo/%d", c.BaseURL, id))
+	if err != nil {
+		return Item{}, err
+	}
+	if len(items) != 1 {
+		return Item{}, fmt.Errorf("%w: Invalid results", ErrInvalid)
+	}
+	return items[0], nil
+}
+
+// Add appends a new item with the given task to the list
+func (c *Client) Add(ctx context.Context, task string) error {
+	return c.AddWithDue(ctx, task, time.Time{})
+}
+
+// AddWithDue appends a new item with the given task and due date to the list,
+// a zero due date adding it without one
+func (c *Client) AddWithDue(ctx context.Context, task string, due time.Time) error {
+	item := struct {
+		Task string `json:"task"`
+		Due  string `json:"due,omitempty"`
+	}{
+		Task: task,
+	}
+	if !due.IsZero() {
+		item.Due = due.Format(time.RFC3339)
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&item); err != nil {
+		return err
+	}
+	return c.sendRequest(ctx, fmt.Sprintf("%s/todo", c.BaseURL), http.MethodPost, "application/json", http.StatusCreated, &body)
+}
+
+// Due returns the pending items overdue, due today or in the next 7 days,
+// as seen from the server clock
+func (c *Client) Due(ctx context.Context) (DueSummary, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/todo/due", c.BaseURL), nil)
+	if err != nil {
+		return DueSummary{}, err
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return DueSummary{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return DueSummary{}, apiError(res)
+	}
+
+	var resp DueSummary
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return DueSummary{}, fmt.Errorf("%w: fail to decode json response: %s", ErrInvalidResponse, err)
+	}
+	return resp, nil
+}
+
+// Complete marks the item with the given ID as completed
+func (c *Client) Complete(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/todo/%d?complete", c.BaseURL, id)
+	return c.sendRequest(ctx, url, http.MethodPatch, "", http.StatusNoContent, nil)
+}
+
+// Delete removes the item with the given ID
+func (c *Client) Delete(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/todo/%d", c.BaseURL, id)
+	return c.sendRequest(ctx, url, http.MethodDelete, "", http.StatusNoContent, nil)
+}
+
+func (c *Client) getItems(ctx context.Context, endpoint string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, apiError(res)
+	}
+
+	var resp todoResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("%w: fail to decode json response: %s", ErrInvalidResponse, err)
+	}
+	if resp.TotalResults == 0 {
+		return nil, fmt.Errorf("%w: No results found", ErrNotFound)
+	}
+	return resp.Results, nil
+}
+
+func (c *Client) sendRequest(ctx context.Context, url, method, contentType string, expStatus int, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != expStatus {
+		return apiError(r)
+	}
+	return nil
+}
+
+func apiError(r *http.Response) error {
+	msg, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("Cannot read body: %w", err)
+	}
+	return &APIError{StatusCode: r.StatusCode, Message: string(msg)}
+}
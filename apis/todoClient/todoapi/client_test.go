@@ -0,0 +1,93 @@
+package todoapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const listBody = `{
+  "results": [
+    {"Task": "Task 1", "Done": false, "CreatedAt": "2019-10-28T08:23:38.310097076-04:00", "CompletedAt": "0001-01-01T00:00:00Z"},
+    {"Task": "Task 2", "Done": true, "CreatedAt": "2019-10-28T08:23:38.323447798-04:00", "CompletedAt": "2019-10-28T09:00:00-04:00"}
+  ],
+  "date": 1572265440,
+  "totalResults": 2
+}`
+
+func TestClient(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, fmt.Sprintf("%s %s %s %s", r.Method, r.URL, r.Header.Get("Authorization"), body))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/todo":
+			fmt.Fprint(w, listBody)
+		case r.Method == http.MethodGet && r.URL.Path == "/todo/9":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Not Found")
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/todo/2":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "broken")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	c := NewClient(ts.URL + "/")
+	c.Token = "secret"
+
+	items, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("Expect no error, got %q", err)
+	}
+	if len(items) != 2 || items[1].Task != "Task 2" || !items[1].Done {
+		t.Errorf("Expect 2 items, got %v", items)
+	}
+
+	_, err = c.Get(ctx, 9)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expect a not found API error, got %q", err)
+	}
+
+	if err := c.Add(ctx, "New task"); err != nil {
+		t.Errorf("Expect no error, got %q", err)
+	}
+	if err := c.Complete(ctx, 2); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("Expect error %q, got %q", ErrInvalidResponse, err)
+	}
+	if err := c.Delete(ctx, 1); err != nil {
+		t.Errorf("Expect no error, got %q", err)
+	}
+
+	expRequests := []string{
+		"GET /todo Bearer secret ",
+		"GET /todo/9 Bearer secret ",
+		"POST /todo Bearer secret {\"task\":\"New task\"}\n",
+		"PATCH /todo/2?complete Bearer secret ",
+		"DELETE /todo/1 Bearer secret ",
+	}
+	if fmt.Sprintf("%q", requests) != fmt.Sprintf("%q", expRequests) {
+		t.Errorf("Expect requests %q, got %q", expRequests, requests)
+	}
+}
+
+func TestClientConnectionError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	if _, err := NewClient(url).List(context.Background()); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+}
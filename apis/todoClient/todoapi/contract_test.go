@@ -0,0 +1,116 @@
+package todoapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"apis/todocontract"
+)
+
+// TestContract replays the interactions of the shared contract against the client
+func TestContract(t *testing.T) {
+	for _, in := range todocontract.Interactions {
+		t.Run(in.Name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != in.Request.Method || r.URL.RequestURI() != in.Request.Path {
+					t.Errorf("Expect request %s %s, got %s %s", in.Request.Method, in.Request.Path, r.Method, r.URL.RequestURI())
+				}
+				if ct := r.Header.Get("Content-Type"); ct != in.Request.ContentType {
+					t.Errorf("Expect content type %q, got %q", in.Request.ContentType, ct)
+				}
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := todocontract.MatchBody(in.Request.ContentType, in.Request.Body, body); err != nil {
+					t.Errorf("Request body: %s", err)
+				}
+
+				if in.Response.ContentType != "" {
+					w.Header().Set("Content-Type", in.Response.ContentType)
+				}
+				w.WriteHeader(in.Response.Status)
+				io.WriteString(w, in.Response.Body)
+			}))
+			defer ts.Close()
+
+			c := NewClient(ts.URL)
+			ctx := context.Background()
+
+			var (
+				items []Item
+				err   error
+			)
+			switch in.Op {
+			case todocontract.OpList:
+				items, err = c.List(ctx)
+			case todocontract.OpGet:
+				var i Item
+				if i, err = c.Get(ctx, in.ID); err == nil {
+					items = []Item{i}
+				}
+			case todocontract.OpAdd:
+				err = c.AddWithDue(ctx, in.Task, in.Due)
+			case todocontract.OpDue:
+				var due DueSummary
+				if due, err = c.Due(ctx); err == nil {
+					for _, i := range due.Overdue {
+						items = append(items, i.Item)
+					}
+				}
+			case todocontract.OpComplete:
+				err = c.Complete(ctx, in.ID)
+			case todocontract.OpDelete:
+				err = c.Delete(ctx, in.ID)
+			default:
+				t.Fatalf("Unknown operation %q", in.Op)
+			}
+
+			var expErr error
+			switch {
+			case in.Response.Status == http.StatusNotFound:
+				expErr = ErrNotFound
+			case in.Response.Status >= http.StatusBadRequest:
+				expErr = ErrInvalidResponse
+			case in.Op == todocontract.OpList && len(in.Items) == 0:
+				// The client reports an empty list as not found
+				expErr = ErrNotFound
+			}
+			if expErr != nil {
+				if !errors.Is(err, expErr) {
+					t.Errorf("Expect error %q, got %q", expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+
+			var expItems []todocontract.Item
+			switch in.Op {
+			case todocontract.OpList:
+				expItems = in.Items
+			case todocontract.OpGet:
+				expItems = in.Items[in.ID-1 : in.ID]
+			case todocontract.OpDue:
+				// The fixtures only hold an overdue item
+				expItems = in.Items[:1]
+			}
+			if len(items) != len(expItems) {
+				t.Fatalf("Expect %d items, got %d", len(expItems), len(items))
+			}
+			for k, exp := range expItems {
+				got := items[k]
+				if got.Task != exp.Task || got.Done != exp.Done ||
+					!got.CreatedAt.Equal(exp.CreatedAt) || !got.CompletedAt.Equal(exp.CompletedAt) ||
+					!got.Due.Equal(exp.Due) {
+					t.Errorf("Expect item %v, got %v", exp, got)
+				}
+			}
+		})
+	}
+}
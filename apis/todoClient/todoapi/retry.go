@@ -0,0 +1,159 @@
+package todoapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how many times and how long apart failed requests are
+// retried. Connection errors, 502, 503 and 504 are retried for GET and HEAD,
+// 429 and 503 with a Retry-After header for every method, honouring it
+type RetryPolicy struct {
+	Retries  int
+	BaseWait time.Duration
+	MaxWait  time.Duration
+	// Sleep waits between attempts, returning early when ctx is done. Mostly
+	// useful in tests, the default waits for the given duration
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// DefaultRetryPolicy retries up to retries times, waiting between 200ms and 5s
+func DefaultRetryPolicy(retries int) RetryPolicy {
+	return RetryPolicy{
+		Retries:  retries,
+		BaseWait: 200 * time.Millisecond,
+		MaxWait:  5 * time.Second,
+	}
+}
+
+// Breaker stops sending requests to a server after repeated failures
+type Breaker interface {
+	// Allow returns an error matching ErrConnection while requests are blocked
+	Allow() error
+	// Record reports the outcome of a request
+	Record(failed bool)
+}
+
+// backoff returns the wait before the given retry, exponential with full jitter
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.BaseWait << attempt
+	if wait <= 0 || wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return rand.N(wait + 1)
+}
+
+func (p RetryPolicy) sleep(ctx context.Context, d time.Duration) error {
+	if p.Sleep != nil {
+		return p.Sleep(ctx, d)
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable reports whether a request can be sent again after a failure, r
+// being nil when no response came. The items are addressed by their position
+// in the list, so POST, PATCH and DELETE aren't idempotent: a delete applied
+// but whose response was lost would delete the next item when sent again.
+// They're only retried when the server rejected them before doing anything,
+// with 429 or 503 and a Retry-After header
+func retryable(method string, r *http.Response) bool {
+	status := 0
+	if r != nil {
+		status = r.StatusCode
+	}
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable && r.Header.Get("Retry-After") != "" {
+		return true
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	switch status {
+	case 0, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as an HTTP date
+func retryAfter(r *http.Response, now time.Time) (time.Duration, bool) {
+	h := r.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// do sends the request, retrying transient failures according to the retry
+// policy and failing fast while the breaker blocks requests
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Breaker != nil {
+		if err := c.Breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.httpClient()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		r, err := client.Do(req)
+		if c.Breaker != nil {
+			c.Breaker.Record(err != nil || r.StatusCode >= http.StatusInternalServerError)
+		}
+
+		if attempt >= c.Retry.Retries || !retryable(req.Method, r) {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrConnection, err)
+			}
+			return r, nil
+		}
+
+		wait := c.Retry.backoff(attempt)
+		if err == nil {
+			if d, ok := retryAfter(r, time.Now()); ok {
+				if d > c.Retry.MaxWait {
+					// The server asked for a longer pause than we're willing to wait
+					return r, nil
+				}
+				wait = d
+			}
+			io.Copy(io.Discard, r.Body)
+			r.Body.Close()
+		}
+
+		if err := c.Retry.sleep(req.Context(), wait); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrConnection, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package todoapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy records the waits instead of sleeping
+func testRetryPolicy(retries int, waits *[]time.Duration) RetryPolicy {
+	p := DefaultRetryPolicy(retries)
+	p.Sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return p
+}
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		name      string
+		method    string
+		failures  []int
+		retryHdr  string
+		expCalls  int
+		expStatus int
+		expWaits  []time.Duration
+	}{
+		{name: "GetRecovers", method: http.MethodGet,
+			failures: []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			expCalls: 3, expStatus: http.StatusOK},
+		{name: "GetGivesUp", method: http.MethodGet,
+			failures: []int{503, 503, 503, 503, 503},
+			expCalls: 4, expStatus: http.StatusServiceUnavailable},
+		{name: "RetryAfter", method: http.MethodGet,
+			failures: []int{http.StatusTooManyRequests}, retryHdr: "2",
+			expCalls: 2, expStatus: http.StatusOK, expWaits: []time.Duration{2 * time.Second}},
+		{name: "RetryAfterTooLong", method: http.MethodGet,
+			failures: []int{http.StatusTooManyRequests}, retryHdr: "60",
+			expCalls: 1, expStatus: http.StatusTooManyRequests, expWaits: []time.Duration{}},
+		{name: "PostNotRetried", method: http.MethodPost,
+			failures: []int{http.StatusServiceUnavailable},
+			expCalls: 1, expStatus: http.StatusServiceUnavailable, expWaits: []time.Duration{}},
+		{name: "PostRateLimited", method: http.MethodPost,
+			failures: []int{http.StatusTooManyRequests}, retryHdr: "1",
+			expCalls: 2, expStatus: http.StatusOK, expWaits: []time.Duration{time.Second}},
+		{name: "DeleteNotRetried", method: http.MethodDelete,
+			failures: []int{http.StatusGatewayTimeout},
+			expCalls: 1, expStatus: http.StatusGatewayTimeout, expWaits: []time.Duration{}},
+		{name: "PatchUnavailable", method: http.MethodPatch,
+			failures: []int{http.StatusServiceUnavailable},
+			expCalls: 1, expStatus: http.StatusServiceUnavailable, expWaits: []time.Duration{}},
+		{name: "PatchUnavailableRetryAfter", method: http.MethodPatch,
+			failures: []int{http.StatusServiceUnavailable}, retryHdr: "1",
+			expCalls: 2, expStatus: http.StatusOK, expWaits: []time.Duration{time.Second}},
+		{name: "NotFoundNotRetried", method: http.MethodGet,
+			failures: []int{http.StatusNotFound},
+			expCalls: 1, expStatus: http.StatusNotFound, expWaits: []time.Duration{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= len(tc.failures) {
+					if tc.retryHdr != "" {
+						w.Header().Set("Retry-After", tc.retryHdr)
+					}
+					w.WriteHeader(tc.failures[calls-1])
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			var waits []time.Duration
+			c := NewClient(ts.URL)
+			c.Retry = testRetryPolicy(3, &waits)
+
+			req, err := http.NewRequest(tc.method, ts.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := c.do(req)
+			if err != nil {
+				t.Fatalf("Expect no error, got %q", err)
+			}
+			r.Body.Close()
+
+			if r.StatusCode != tc.expStatus {
+				t.Errorf("Expect status %d, got %d", tc.expStatus, r.StatusCode)
+			}
+			if calls != tc.expCalls {
+				t.Errorf("Expect %d calls, got %d", tc.expCalls, calls)
+			}
+			if tc.expWaits != nil && fmt.Sprint(waits) != fmt.Sprint(tc.expWaits) {
+				t.Errorf("Expect waits %v, got %v", tc.expWaits, waits)
+			}
+			for _, w := range waits {
+				if w < 0 || w > c.Retry.MaxWait {
+					t.Errorf("Expect wait between 0 and %s, got %s", c.Retry.MaxWait, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPostBody(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b [64]byte
+		n, _ := r.Body.Read(b[:])
+		bodies = append(bodies, string(b[:n]))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := NewClient(ts.URL)
+	c.Retry = testRetryPolicy(3, &waits)
+	if err := c.Add(context.Background(), "Task 1"); err != nil {
+		t.Fatalf("Expect no error, got %q", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] {
+		t.Errorf("Expect the same body sent twice, got %q", bodies)
+	}
+}
+
+func TestRetryDeleteTimeout(t *testing.T) {
+	// The server deletes the item but its response comes too late
+	var deletes atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deletes.Add(1)
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := NewClient(ts.URL)
+	c.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+	c.Retry = testRetryPolicy(3, &waits)
+	if err := c.Delete(context.Background(), 1); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+	if n := deletes.Load(); n != 1 {
+		t.Errorf("Expect the delete sent once, got %d", n)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient(ts.URL)
+	c.Retry = DefaultRetryPolicy(3)
+	c.Retry.BaseWait = time.Hour
+	c.Retry.MaxWait = time.Hour
+
+	cancel()
+	if _, err := c.List(ctx); !errors.Is(err, ErrConnection) {
+		t.Errorf("Expect error %q, got %q", ErrConnection, err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo"
+
+	"apis/todocontract"
+)
+
+// TestContract replays the interactions of the shared contract against the routes
+func TestContract(t *testing.T) {
+	for _, in := range todocontract.Interactions {
+		t.Run(in.Name, func(t *testing.T) {
+			todoFile := filepath.Join(t.TempDir(), "todo.json")
+			l := &todo.List{}
+			for _, i := range in.Items {
+				l.Items = append(l.Items, todo.Item{Task: i.Task, Done: i.Done, CreatedAt: i.CreatedAt, CompletedAt: i.CompletedAt, Due: i.Due})
+			}
+			if err := l.Save(todoFile); err != nil {
+				t.Fatal(err)
+			}
+
+			ts := newTestServer(t, newTodoStore(todoFile), serverConfig{})
+			defer ts.Close()
+
+			req, err := http.NewRequest(in.Request.Method, ts.URL+in.Request.Path, strings.NewReader(in.Request.Body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if in.Request.ContentType != "" {
+				req.Header.Set("Content-Type", in.Request.ContentType)
+			}
+
+			r, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Body.Close()
+
+			if r.StatusCode != in.Response.Status {
+				t.Errorf("Expect status %d, got %d", in.Response.Status, r.StatusCode)
+			}
+			if ct := r.Header.Get("Content-Type"); in.Response.ContentType != "" && !strings.HasPrefix(ct, in.Response.ContentType) {
+				t.Errorf("Expect content type %q, got %q", in.Response.ContentType, ct)
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := in.Response.Match(body); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
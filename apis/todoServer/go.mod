@@ -1,7 +1,26 @@
 module apis/todoServer
 
-go 1.23.1
+go 1.25.0
 
 require todo v0.0.0
 
+require (
+	apis/todopb v0.0.0
+	github.com/graphql-go/graphql v0.8.1
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	apis/todocontract v0.0.0
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+)
+
 replace todo => ../../todo
+
+replace apis/todopb => ../todopb
+
+replace apis/todocontract => ../todocontract
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo"
+
+	"github.com/graphql-go/graphql"
+)
+
+// graphqlEnv gives the resolvers access to the list shared with the REST routes
+type graphqlEnv struct {
+	list          *todo.List
+	todoFile      string
+	maxTaskLength int
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+var todoType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Todo",
+	Description: "A todo item. The id is its position in the list, starting at 1",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"task":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"done":        &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"completedAt": &graphql.Field{Type: graphql.DateTime},
+		"due":         &graphql.Field{Type: graphql.DateTime, Description: "When the item is due, null when it has no due date"},
+	},
+})
+
+var todoPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "TodoPage",
+	Description: "A page of todo items matching a query",
+	Fields: graphql.Fields{
+		"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType)))},
+		"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Number of items matching the filters"},
+		"hasMore":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+	},
+})
+
+var idArgs = graphql.FieldConfigArgument{
+	"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+}
+
+var todoSchema = mustSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type: graphql.NewNonNull(todoPageType),
+				Args: graphql.FieldConfigArgument{
+					"done":   &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Only items with this completion status"},
+					"search": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only items whose task contains this text, case insensitive"},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "Maximum number of items, all when omitted"},
+				},
+				Resolve: resolveTodos,
+			},
+			"todo": &graphql.Field{
+				Type:    todoType,
+				Args:    idArgs,
+				Resolve: resolveTodo,
+			},
+		},
+	}),
+	Mutation: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"addTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"task": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"due":  &graphql.ArgumentConfig{Type: graphql.DateTime, Description: "Due date as an RFC 3339 time"},
+				},
+				Resolve: resolveAddTodo,
+			},
+			"completeTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(todoType),
+				Args:    idArgs,
+				Resolve: resolveCompleteTodo,
+			},
+			"deleteTodo": &graphql.Field{
+				Type:        graphql.NewNonNull(todoType),
+				Description: "Deletes an item and returns it as it was before deletion",
+				Args:        idArgs,
+				Resolve:     resolveDeleteTodo,
+			},
+		},
+	}),
+})
+
+func mustSchema(config graphql.SchemaConfig) graphql.Schema {
+	s, err := graphql.NewSchema(config)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func graphqlHandler(w http.ResponseWriter, r *http.Request, env *graphqlEnv) {
+	var req graphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		replyBodyError(w, r, err)
+		return
+	}
+
+	if err := env.list.Get(env.todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlEnvKey, env)
+	result := graphql.Do(graphql.Params{
+		Schema:         todoSchema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		slog.ErrorContext(r.Context(), "error writing response", "request_id", requestID(r.Context()), "error", err)
+	}
+}
+
+func envFrom(p graphql.ResolveParams) *graphqlEnv {
+	return p.Context.Value(graphqlEnvKey).(*graphqlEnv)
+}
+
+// graphqlItem converts an item to the Todo GraphQL type
+func graphqlItem(id int, item todo.Item) map[string]any {
+	var completedAt, due *time.Time
+	if item.Done {
+		completedAt = &item.CompletedAt
+	}
+	if !item.Due.IsZero() {
+		due = &item.Due
+	}
+	return map[string]any{
+		"id":          id,
+		"task":        item.Task,
+		"done":        item.Done,
+		"createdAt":   item.CreatedAt,
+		"completedAt": completedAt,
+		"due":         due,
+	}
+}
+
+func graphqlID(p graphql.ResolveParams, list *todo.List) (int, error) {
+	id, _ := p.Args["id"].(int)
+	return validateID(strconv.Itoa(id), list)
+}
+
+func resolveTodos(p graphql.ResolveParams) (any, error) {
+	env := envFrom(p)
+	done, filterDone := p.Args["done"].(bool)
+	search, filterSearch := p.Args["search"].(string)
+	search = strings.ToLower(search)
+	offset, _ := p.Args["offset"].(int)
+	limit, hasLimit := p.Args["limit"].(int)
+
+	if offset < 0 || (hasLimit && limit < 0) {
+		return nil, fmt.Errorf("%w: offset and limit cannot be negative", ErrInvalidData)
+	}
+
+	matches := []map[string]any{}
+	for k, item := range env.list.Items {
+		if filterDone && item.Done != done {
+			continue
+		}
+		if filterSearch && !strings.Contains(strings.ToLower(item.Task), search) {
+			continue
+		}
+		matches = append(matches, graphqlItem(k+1, item))
+	}
+
+	total := len(matches)
+	end := total
+	if hasLimit && offset+limit < total {
+		end = offset + limit
+	}
+	items := []map[string]any{}
+	if offset < total {
+		items = matches[offset:end]
+	}
+
+	return map[string]any{
+		"items":      items,
+		"totalCount": total,
+		"hasMore":    end < total,
+	}, nil
+}
+
+func resolveTodo(p graphql.ResolveParams) (any, error) {
+	env := envFrom(p)
+	id, err := graphqlID(p, env.list)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlItem(id, env.list.Items[id-1]), nil
+}
+
+func resolveAddTodo(p graphql.ResolveParams) (any, error) {
+	env := envFrom(p)
+	task, _ := p.Args["task"].(string)
+	if task == "" {
+		return nil, fmt.Errorf("%w: task cannot be blank", ErrInvalidData)
+	}
+	if err := validateTask(task, env.maxTaskLength); err != nil {
+		return nil, err
+	}
+
+	env.list.Add(task)
+	id := len(env.list.Items)
+	if due, ok := p.Args["due"].(time.Time); ok {
+		if err := env.list.SetDue(id, due); err != nil {
+			return nil, err
+		}
+	}
+	if err := env.list.Save(env.todoFile); err != nil {
+		return nil, err
+	}
+
+	return graphqlItem(id, env.list.Items[id-1]), nil
+}
+
+func resolveCompleteTodo(p graphql.ResolveParams) (any, error) {
+	env := envFrom(p)
+	id, err := graphqlID(p, env.list)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.list.Complete(id); err != nil {
+		return nil, err
+	}
+	if err := env.list.Save(env.todoFile); err != nil {
+		return nil, err
+	}
+
+	return graphqlItem(id, env.list.Items[id-1]), nil
+}
+
+func resolveDeleteTodo(p graphql.ResolveParams) (any, error) {
+	env := envFrom(p)
+	id, err := graphqlID(p, env.list)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := graphqlItem(id, env.list.Items[id-1])
+	if err := env.list.Delete(id); err != nil {
+		return nil, err
+	}
+	if err := env.list.Save(env.todoFile); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
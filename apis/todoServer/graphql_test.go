@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func graphqlQuery(t *testing.T, serverUrl, query string, variables map[string]any) graphqlResponse {
+	t.Helper()
+	body, err := json.Marshal(graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.Post(serverUrl+"/graphql", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+	}
+
+	var resp graphqlResponse
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+type graphqlTodo struct {
+	ID          int     `json:"id"`
+	Task        string  `json:"task"`
+	Done        bool    `json:"done"`
+	CompletedAt *string `json:"completedAt"`
+	Due         *string `json:"due"`
+}
+
+func TestGraphQLTodos(t *testing.T) {
+	serverUrl, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	testCases := []struct {
+		name       string
+		query      string
+		expTotal   int
+		expTasks   []string
+		expHasMore bool
+	}{
+		{name: "All", query: `{ todos { totalCount hasMore items { id task } } }`, expTotal: 2, expTasks: []string{"Task number 1.", "Task number 2."}},
+		{name: "Search", query: `{ todos(search: "NUMBER 2") { totalCount hasMore items { id task } } }`, expTotal: 1, expTasks: []string{"Task number 2."}},
+		{name: "Done", query: `{ todos(done: true) { totalCount hasMore items { id task } } }`, expTotal: 0, expTasks: []string{}},
+		{name: "Paginated", query: `{ todos(limit: 1) { totalCount hasMore items { id task } } }`, expTotal: 2, expTasks: []string{"Task number 1."}, expHasMore: true},
+		{name: "Offset", query: `{ todos(offset: 1, limit: 1) { totalCount hasMore items { id task } } }`, expTotal: 2, expTasks: []string{"Task number 2."}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := graphqlQuery(t, serverUrl, tc.query, nil)
+			if len(resp.Errors) > 0 {
+				t.Fatalf("Expect no errors, got %v", resp.Errors)
+			}
+
+			var page struct {
+				TotalCount int           `json:"totalCount"`
+				HasMore    bool          `json:"hasMore"`
+				Items      []graphqlTodo `json:"items"`
+			}
+			if err := json.Unmarshal(resp.Data["todos"], &page); err != nil {
+				t.Fatal(err)
+			}
+
+			if page.TotalCount != tc.expTotal {
+				t.Errorf("Expect totalCount %d, got %d", tc.expTotal, page.TotalCount)
+			}
+			if page.HasMore != tc.expHasMore {
+				t.Errorf("Expect hasMore %t, got %t", tc.expHasMore, page.HasMore)
+			}
+			if len(page.Items) != len(tc.expTasks) {
+				t.Fatalf("Expect %d items, got %d", len(tc.expTasks), len(page.Items))
+			}
+			for k, task := range tc.expTasks {
+				if page.Items[k].Task != task {
+					t.Errorf("Expect task %q, got %q", task, page.Items[k].Task)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	serverUrl, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	decode := func(t *testing.T, resp graphqlResponse, field string) graphqlTodo {
+		t.Helper()
+		if len(resp.Errors) > 0 {
+			t.Fatalf("Expect no errors, got %v", resp.Errors)
+		}
+		var item graphqlTodo
+		if err := json.Unmarshal(resp.Data[field], &item); err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+
+	t.Run("Add", func(t *testing.T) {
+		resp := graphqlQuery(t, serverUrl, `mutation($task: String!) { addTodo(task: $task) { id task done } }`,
+			map[string]any{"task": "foo"})
+		item := decode(t, resp, "addTodo")
+		if item.ID != 3 || item.Task != "foo" || item.Done {
+			t.Errorf("Unexpected item %+v", item)
+		}
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		resp := graphqlQuery(t, serverUrl, `mutation { completeTodo(id: 1) { id done completedAt } }`, nil)
+		item := decode(t, resp, "completeTodo")
+		if !item.Done || item.CompletedAt == nil {
+			t.Errorf("Expect item to be completed, got %+v", item)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		resp := graphqlQuery(t, serverUrl, `mutation { deleteTodo(id: 2) { task } }`, nil)
+		item := decode(t, resp, "deleteTodo")
+		if item.Task != "Task number 2." {
+			t.Errorf("Expect deleted task %q, got %q", "Task number 2.", item.Task)
+		}
+
+		resp = graphqlQuery(t, serverUrl, `{ todos { totalCount } }`, nil)
+		if string(resp.Data["todos"]) != `{"totalCount":2}` {
+			t.Errorf("Expect 2 items left, got %s", resp.Data["todos"])
+		}
+	})
+
+	t.Run("AddDue", func(t *testing.T) {
+		resp := graphqlQuery(t, serverUrl, `mutation($due: DateTime) { addTodo(task: "bar", due: $due) { id due } }`,
+			map[string]any{"due": "2999-01-02T15:04:05Z"})
+		item := decode(t, resp, "addTodo")
+		if item.ID != 3 || item.Due == nil || *item.Due != "2999-01-02T15:04:05Z" {
+			t.Errorf("Expect item 3 due %q, got %+v", "2999-01-02T15:04:05Z", item)
+		}
+
+		resp = graphqlQuery(t, serverUrl, `{ todo(id: 1) { due } }`, nil)
+		if string(resp.Data["todo"]) != `{"due":null}` {
+			t.Errorf("Expect no due date, got %s", resp.Data["todo"])
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		resp := graphqlQuery(t, serverUrl, `mutation { completeTodo(id: 10) { id } }`, nil)
+		if len(resp.Errors) != 1 {
+			t.Fatalf("Expect 1 error, got %v", resp.Errors)
+		}
+	})
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	serverUrl, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	resp := graphqlQuery(t, serverUrl, `{ __schema { mutationType { fields { name } } } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Expect no errors, got %v", resp.Errors)
+	}
+
+	var schema struct {
+		MutationType struct {
+			Fields []struct {
+				Name string `json:"name"`
+			} `json:"fields"`
+		} `json:"mutationType"`
+	}
+	if err := json.Unmarshal(resp.Data["__schema"], &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]bool{}
+	for _, f := range schema.MutationType.Fields {
+		names[f.Name] = true
+	}
+	for _, name := range []string{"addTodo", "completeTodo", "deleteTodo"} {
+		if !names[name] {
+			t.Errorf("Expect mutation %q in schema", name)
+		}
+	}
+}
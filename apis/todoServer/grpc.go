@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"todo"
+
+	"apis/todopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer implements the TodoService on top of the store shared with the HTTP routes
+type grpcServer struct {
+	todopb.UnimplementedTodoServiceServer
+	store         *todoStore
+	maxTaskLength int
+}
+
+func newGRPCServer(store *todoStore, cfg serverConfig) *grpc.Server {
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcLoggingInterceptor))
+	todopb.RegisterTodoServiceServer(s, &grpcServer{store: store, maxTaskLength: cfg.maxTaskLength})
+	return s
+}
+
+func grpcLoggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "grpc error", "method", info.FullMethod, "code", status.Code(err).String(), "error", err)
+	}
+	return resp, err
+}
+
+func (s *grpcServer) ListTodos(ctx context.Context, req *todopb.ListTodosRequest) (*todopb.ListTodosResponse, error) {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return pbList(s.store.list), nil
+}
+
+func (s *grpcServer) GetTodo(ctx context.Context, req *todopb.GetTodoRequest) (*todopb.Todo, error) {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	id, err := s.validateID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return pbTodo(id, s.store.list.Items[id-1]), nil
+}
+
+func (s *grpcServer) AddTodo(ctx context.Context, req *todopb.AddTodoRequest) (*todopb.Todo, error) {
+	if req.GetTask() == "" {
+		return nil, status.Error(codes.InvalidArgument, "task cannot be blank")
+	}
+	if err := validateTask(req.GetTask(), s.maxTaskLength); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	defer s.store.notify()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	list := s.store.list
+	list.Add(req.GetTask())
+	if req.GetDue() != nil {
+		if err := list.SetDue(len(list.Items), req.GetDue().AsTime()); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	if err := list.Save(s.store.todoFile); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	id := len(list.Items)
+	return pbTodo(id, list.Items[id-1]), nil
+}
+
+func (s *grpcServer) CompleteTodo(ctx context.Context, req *todopb.CompleteTodoRequest) (*todopb.Todo, error) {
+	s.store.Lock()
+	defer s.store.Unlock()
+	defer s.store.notify()
+
+	id, err := s.validateID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	list := s.store.list
+	if err := list.Complete(id); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := list.Save(s.store.todoFile); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return pbTodo(id, list.Items[id-1]), nil
+}
+
+func (s *grpcServer) DeleteTodo(ctx context.Context, req *todopb.DeleteTodoRequest) (*todopb.DeleteTodoResponse, error) {
+	s.store.Lock()
+	defer s.store.Unlock()
+	defer s.store.notify()
+
+	id, err := s.validateID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	list := s.store.list
+	if err := list.Delete(id); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := list.Save(s.store.todoFile); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &todopb.DeleteTodoResponse{}, nil
+}
+
+func (s *grpcServer) WatchTodos(req *todopb.WatchTodosRequest, stream grpc.ServerStreamingServer[todopb.ListTodosResponse]) error {
+	changed, stop := s.store.watch()
+	defer stop()
+
+	var (
+		last []todo.Item
+		sent bool
+	)
+	send := func() error {
+		s.store.Lock()
+		if err := s.store.list.Get(s.store.todoFile); err != nil {
+			s.store.Unlock()
+			return status.Error(codes.Internal, err.Error())
+		}
+		if sent && slices.EqualFunc(last, s.store.list.Items, sameItem) {
+			// Nothing changed since the last message
+			s.store.Unlock()
+			return nil
+		}
+		last, sent = slices.Clone(s.store.list.Items), true
+		resp := pbList(s.store.list)
+		s.store.Unlock()
+
+		return stream.Send(resp)
+	}
+
+	if err := send(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-changed:
+			if err := send(); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// reload reads the list from the file, which other servers or the CLI may
+// have changed. The caller must hold the store lock
+func (s *grpcServer) reload() error {
+	if err := s.store.list.Get(s.store.todoFile); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// validateID reloads the list and checks the id against it, the caller must
+// hold the store lock
+func (s *grpcServer) validateID(id int64) (int, error) {
+	if err := s.reload(); err != nil {
+		return 0, err
+	}
+	i, err := validateID(strconv.FormatInt(id, 10), s.store.list)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, status.Error(codes.NotFound, err.Error())
+		}
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return i, nil
+}
+
+func sameItem(a, b todo.Item) bool {
+	return a.Task == b.Task && a.Done == b.Done &&
+		a.CreatedAt.Equal(b.CreatedAt) && a.CompletedAt.Equal(b.CompletedAt) && a.Due.Equal(b.Due)
+}
+
+func pbList(list *todo.List) *todopb.ListTodosResponse {
+	resp := &todopb.ListTodosResponse{
+		Todos: make([]*todopb.Todo, 0, len(list.Items)),
+		Date:  timestamppb.Now(),
+	}
+	for k, item := range list.Items {
+		resp.Todos = append(resp.Todos, pbTodo(k+1, item))
+	}
+	return resp
+}
+
+func pbTodo(id int, item todo.Item) *todopb.Todo {
+	t := &todopb.Todo{
+		Id:        int64(id),
+		Task:      item.Task,
+		Done:      item.Done,
+		CreatedAt: timestamppb.New(item.CreatedAt),
+	}
+	if item.Done {
+		t.CompletedAt = timestamppb.New(item.CompletedAt)
+	}
+	if !item.Due.IsZero() {
+		t.Due = timestamppb.New(item.Due)
+	}
+	return t
+}
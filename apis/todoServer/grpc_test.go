@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+	"todo"
+
+	"apis/todopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func setupGRPCServer(t *testing.T) todopb.TodoServiceClient {
+	t.Helper()
+
+	tempFile, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := newTodoStore(tempFile.Name())
+	store.list.Add("Task number 1.")
+	store.list.Add("Task number 2.")
+	if err := store.list.Save(store.todoFile); err != nil {
+		t.Fatal(err)
+	}
+
+	return serveGRPC(t, store)
+}
+
+// serveGRPC serves the store over an in-memory listener, returning a client of it
+func serveGRPC(t *testing.T, store *todoStore) todopb.TodoServiceClient {
+	t.Helper()
+
+	l := bufconn.Listen(1024 * 1024)
+	s := newGRPCServer(store, serverConfig{})
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return todopb.NewTodoServiceClient(conn)
+}
+
+func TestGRPCService(t *testing.T) {
+	client := setupGRPCServer(t)
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		resp, err := client.ListTodos(ctx, &todopb.ListTodosRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.GetTodos()) != 2 {
+			t.Fatalf("Expect 2 items, got %d", len(resp.GetTodos()))
+		}
+		if resp.GetTodos()[1].GetTask() != "Task number 2." {
+			t.Errorf("Expect task %q, got %q", "Task number 2.", resp.GetTodos()[1].GetTask())
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		todo, err := client.GetTodo(ctx, &todopb.GetTodoRequest{Id: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if todo.GetTask() != "Task number 1." {
+			t.Errorf("Expect task %q, got %q", "Task number 1.", todo.GetTask())
+		}
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		_, err := client.GetTodo(ctx, &todopb.GetTodoRequest{Id: 10})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expect code %s, got %s", codes.NotFound, status.Code(err))
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		due := time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)
+		todo, err := client.AddTodo(ctx, &todopb.AddTodoRequest{Task: "foo", Due: timestamppb.New(due)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if todo.GetId() != 3 || todo.GetTask() != "foo" {
+			t.Errorf("Unexpected item %v", todo)
+		}
+		if !todo.GetDue().AsTime().Equal(due) {
+			t.Errorf("Expect due %s, got %v", due, todo.GetDue())
+		}
+	})
+
+	t.Run("AddBlank", func(t *testing.T) {
+		_, err := client.AddTodo(ctx, &todopb.AddTodoRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expect code %s, got %s", codes.InvalidArgument, status.Code(err))
+		}
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		todo, err := client.CompleteTodo(ctx, &todopb.CompleteTodoRequest{Id: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !todo.GetDone() || todo.GetCompletedAt() == nil {
+			t.Errorf("Expect item to be completed, got %v", todo)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if _, err := client.DeleteTodo(ctx, &todopb.DeleteTodoRequest{Id: 2}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.ListTodos(ctx, &todopb.ListTodosRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.GetTodos()) != 2 {
+			t.Errorf("Expect 2 items, got %d", len(resp.GetTodos()))
+		}
+	})
+}
+
+func TestGRPCReload(t *testing.T) {
+	tempFile, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file exists before the server starts, with an empty list in memory
+	saved := &todo.List{}
+	saved.Add("Task number 1.")
+	saved.Add("Task number 2.")
+	if err := saved.Save(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	client := serveGRPC(t, newTodoStore(tempFile.Name()))
+	ctx := context.Background()
+
+	got, err := client.GetTodo(ctx, &todopb.GetTodoRequest{Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetTask() != "Task number 2." {
+		t.Errorf("Expect task %q, got %q", "Task number 2.", got.GetTask())
+	}
+
+	added, err := client.AddTodo(ctx, &todopb.AddTodoRequest{Task: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.GetId() != 3 {
+		t.Errorf("Expect id 3, got %d", added.GetId())
+	}
+
+	if _, err := client.CompleteTodo(ctx, &todopb.CompleteTodoRequest{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteTodo(ctx, &todopb.DeleteTodoRequest{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &todo.List{}
+	if err := l.Get(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Items) != 2 {
+		t.Fatalf("Expect 2 items in the file, got %d", len(l.Items))
+	}
+	if l.Items[0].Task != "Task number 1." || !l.Items[0].Done {
+		t.Errorf("Expect the first item completed, got %v", l.Items[0])
+	}
+	if l.Items[1].Task != "foo" {
+		t.Errorf("Expect task %q, got %q", "foo", l.Items[1].Task)
+	}
+}
+
+func TestGRPCWatch(t *testing.T) {
+	client := setupGRPCServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchTodos(ctx, &todopb.WatchTodosRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetTodos()) != 2 {
+		t.Fatalf("Expect initial list of 2 items, got %d", len(resp.GetTodos()))
+	}
+
+	if _, err := client.AddTodo(ctx, &todopb.AddTodoRequest{Task: "watched"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetTodos()) != 3 {
+		t.Fatalf("Expect updated list of 3 items, got %d", len(resp.GetTodos()))
+	}
+	if resp.GetTodos()[2].GetTask() != "watched" {
+		t.Errorf("Expect task %q, got %q", "watched", resp.GetTodos()[2].GetTask())
+	}
+}
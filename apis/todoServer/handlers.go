@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 	"todo"
+	"unicode/utf8"
 )
 
 var (
@@ -14,15 +16,6 @@ var (
 	ErrNotFound    error = errors.New("not found")
 )
 
-func rootHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
-		return
-	}
-	content := "hello there you hit the api"
-	replyPlainText(w, r, http.StatusOK, content)
-}
-
 func getTodoRouter(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string) {
 	if err := list.Get(todoFile); err != nil {
 		replyError(w, r, http.StatusInternalServerError, err.Error())
@@ -41,17 +34,49 @@ func getSingleTodoRouter(w http.ResponseWriter, r *http.Request, list *todo.List
 	replyJSONContent(w, r, http.StatusOK, resp)
 }
 
-func addTodoRouter(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string) {
+func dueTodoRouter(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string) {
+	if err := list.Get(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &dueResponse{Report: list.Due(time.Now())}
+	replyJSONContent(w, r, http.StatusOK, resp)
+}
+
+func addTodoRouter(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string, maxTaskLength int) {
 	// Add todo
 	item := struct {
 		Task string `json:"task"`
+		// Due is an optional date or RFC 3339 time
+		Due string `json:"due"`
 	}{}
 
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		replyBodyError(w, r, err)
+		return
+	}
+
+	if err := validateTask(item.Task, maxTaskLength); err != nil {
 		replyError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var due time.Time
+	if item.Due != "" {
+		d, err := todo.ParseDue(item.Due, time.Local)
+		if err != nil {
+			replyError(w, r, http.StatusBadRequest, fmt.Sprintf("%s: %s", ErrInvalidData, err))
+			return
+		}
+		due = d
 	}
 
 	list.Add(item.Task)
+	if err := list.SetDue(len(list.Items), due); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	if err := list.Save(todoFile); err != nil {
 		replyError(w, r, http.StatusInternalServerError, err.Error())
@@ -89,6 +114,14 @@ func pacthHandler(w http.ResponseWriter, r *http.Request, list *todo.List, id in
 	replyPlainText(w, r, http.StatusNoContent, "")
 }
 
+func validateTask(task string, maxLength int) error {
+	if maxLength > 0 && utf8.RuneCountInString(task) > maxLength {
+		return fmt.Errorf("%w: task longer than %d characters", ErrInvalidData, maxLength)
+	}
+
+	return nil
+}
+
 func validateID(idString string, list *todo.List) (int, error) {
 	id, err := strconv.Atoi(idString)
 	if err != nil {
@@ -3,7 +3,9 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -13,19 +15,89 @@ func main() {
 	// Parse flag
 	host := flag.String("h", "localhost", "Server host")
 	port := flag.Int("p", 8080, "Server port")
+	grpcPort := flag.Int("grpc-port", 0, "gRPC server port, 0 to disable")
 	todoFile := flag.String("f", ".todo.json", "todo JSON file")
+	logFormat := flag.String("log-format", "text", "Log format: text or json")
+	maxBody := flag.Int64("max-body", 1<<20, "Maximum request body size in bytes, 0 for no limit")
+	maxTask := flag.Int("max-task", 1024, "Maximum task length in characters, 0 for no limit")
+	rate := flag.String("rate", "10:20", "Default rate limit per client as requests per second:burst, 0 to disable")
+	rateBy := flag.String("rate-by", "ip", "Identify rate limited clients by: ip or token")
+	routeLimits := routeLimits{}
+	flag.Var(routeLimits, "route-rate", `Rate limit for a single route as "METHOD /path=rate:burst", can be repeated`)
 	flag.Parse()
 
+	logger, err := newLogger(os.Stderr, *logFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	defaultLimit, err := parseRateLimit(*rate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	clientKey, err := clientKeyFunc(*rateBy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg := serverConfig{
+		maxBodyBytes:  *maxBody,
+		maxTaskLength: *maxTask,
+		rateLimit:     defaultLimit,
+		routeLimits:   routeLimits,
+		clientKey:     clientKey,
+	}
+
+	store := newTodoStore(*todoFile)
+	mux, err := newMux(store, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *grpcPort > 0 {
+		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", *host, *grpcPort))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		gs := newGRPCServer(store, cfg)
+		go func() {
+			slog.Info("gRPC server start listening", "port", *grpcPort)
+			if err := gs.Serve(l); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	s := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", *host, *port),
-		Handler:      newMux(*todoFile),
+		Handler:      mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
-	log.Printf("Server start listening on port: %d", *port)
+	slog.Info("Server start listening", "port", *port)
 	if err := s.ListenAndServe(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// newLogger creates a structured logger writing to out in the given format
+func newLogger(out io.Writer, format string) (*slog.Logger, error) {
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(out, nil)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, nil)), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown log format %q", ErrInvalidData, format)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reqID    string
+		expReuse bool
+	}{
+		{name: "Generated", reqID: "", expReuse: false},
+		{name: "Propagated", reqID: "abc-123", expReuse: true},
+		{name: "InvalidReplaced", reqID: "bad id\n", expReuse: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctxID string
+			h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = requestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/todo", nil)
+			if tc.reqID != "" {
+				req.Header.Set(requestIDHeader, tc.reqID)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			respID := rec.Header().Get(requestIDHeader)
+			if respID == "" {
+				t.Fatal("Expected response to have a request ID")
+			}
+			if respID != ctxID {
+				t.Errorf("Expected context request ID %q, got %q", respID, ctxID)
+			}
+			if tc.expReuse && respID != tc.reqID {
+				t.Errorf("Expected request ID %q, got %q", tc.reqID, respID)
+			}
+			if !tc.expReuse && respID == tc.reqID {
+				t.Errorf("Expected a new request ID, got %q", respID)
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replyError(w, r, http.StatusTeapot, "short and stout")
+	}), requestIDMiddleware, loggingMiddleware)
+
+	req := httptest.NewRequest(http.MethodGet, "/todo", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entries []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e map[string]any
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d: %s", len(entries), buf.String())
+	}
+	for _, e := range entries {
+		if e["request_id"] != "req-1" {
+			t.Errorf("Expected request_id %q, got %v", "req-1", e["request_id"])
+		}
+	}
+
+	access := entries[1]
+	if access["status"] != float64(http.StatusTeapot) {
+		t.Errorf("Expected status %d, got %v", http.StatusTeapot, access["status"])
+	}
+	if access["size"] != float64(len("short and stout\n")) {
+		t.Errorf("Expected size %d, got %v", len("short and stout\n"), access["size"])
+	}
+	if _, ok := access["duration"]; !ok {
+		t.Error("Expected access log to have a duration")
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		if _, err := newLogger(&bytes.Buffer{}, format); err != nil {
+			t.Errorf("Expected no error for format %q, got %q", format, err)
+		}
+	}
+	if _, err := newLogger(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimit defines a token bucket refilled at Rate tokens per second
+// holding at most Burst tokens. A zero Rate disables the limit
+type rateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l rateLimit) String() string {
+	return fmt.Sprintf("%g:%d", l.Rate, l.Burst)
+}
+
+// parseRateLimit parses a limit in the form "rate:burst" or "rate"
+func parseRateLimit(s string) (rateLimit, error) {
+	rate, burst, found := strings.Cut(s, ":")
+	r, err := strconv.ParseFloat(rate, 64)
+	if err != nil || r < 0 {
+		return rateLimit{}, fmt.Errorf("%w: invalid rate %q", ErrInvalidData, rate)
+	}
+
+	l := rateLimit{Rate: r, Burst: int(math.Ceil(r))}
+	if found {
+		if l.Burst, err = strconv.Atoi(burst); err != nil || l.Burst < 1 {
+			return rateLimit{}, fmt.Errorf("%w: invalid burst %q", ErrInvalidData, burst)
+		}
+	}
+	if l.Burst < 1 {
+		l.Burst = 1
+	}
+	return l, nil
+}
+
+// routeLimits maps a route pattern such as "POST /todo" to its rate limit.
+// It implements flag.Value so it can be set multiple times from the command line
+type routeLimits map[string]rateLimit
+
+func (rl routeLimits) String() string {
+	routes := make([]string, 0, len(rl))
+	for route, l := range rl {
+		routes = append(routes, fmt.Sprintf("%s=%s", route, l))
+	}
+	return strings.Join(routes, ",")
+}
+
+// Set parses a route limit in the form "METHOD /path=rate:burst"
+func (rl routeLimits) Set(s string) error {
+	route, limit, found := strings.Cut(s, "=")
+	if !found || strings.TrimSpace(route) == "" {
+		return fmt.Errorf("%w: expected METHOD /path=rate:burst, got %q", ErrInvalidData, s)
+	}
+
+	l, err := parseRateLimit(limit)
+	if err != nil {
+		return err
+	}
+	rl[strings.TrimSpace(route)] = l
+	return nil
+}
+
+// routeLimiter wraps the routes with their rate limit. The routes without a
+// limit of their own share the default limiter, so the default rate applies
+// to a client across all of them and not on each route
+type routeLimiter struct {
+	limits   routeLimits
+	fallback *rateLimiter
+	keyFunc  func(*http.Request) string
+	// routes are the patterns limited so far
+	routes map[string]bool
+}
+
+func newRouteLimiter(c serverConfig) *routeLimiter {
+	rl := &routeLimiter{limits: c.routeLimits, keyFunc: c.clientKey, routes: map[string]bool{}}
+	if rl.keyFunc == nil {
+		rl.keyFunc = clientIP
+	}
+	if c.rateLimit.Rate > 0 {
+		rl.fallback = newRateLimiter(c.rateLimit)
+	}
+	return rl
+}
+
+// limit wraps h with the rate limit of the route pattern, if any
+func (rl *routeLimiter) limit(pattern string, h http.Handler) http.Handler {
+	rl.routes[pattern] = true
+	limiter := rl.fallback
+	if l, ok := rl.limits[pattern]; ok {
+		limiter = nil
+		if l.Rate > 0 {
+			limiter = newRateLimiter(l)
+		}
+	}
+	if limiter == nil {
+		return h
+	}
+	return rateLimitMiddleware(limiter, rl.keyFunc)(h)
+}
+
+// unknown returns an error naming the route limits set on no route limited
+func (rl *routeLimiter) unknown() error {
+	var unknown []string
+	for pattern := range rl.limits {
+		if !rl.routes[pattern] {
+			unknown = append(unknown, strconv.Quote(pattern))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("%w: rate limit for unknown route %s", ErrInvalidData, strings.Join(unknown, ", "))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per client key
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     rateLimit
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter(l rateLimit) *rateLimiter {
+	return &rateLimiter{
+		limit:   l,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of key. When the bucket is empty
+// it returns false and how long the client should wait before retrying
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rl.limit.Burst), last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(rl.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*rl.limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / rl.limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep removes the buckets that would be full by now so idle clients don't pile up
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+
+	fill := time.Duration(float64(rl.limit.Burst) / rl.limit.Rate * float64(time.Second))
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) > fill {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// rateLimitMiddleware rejects the requests exceeding the limit of their client
+// with 429 Too Many Requests and a Retry-After header
+func rateLimitMiddleware(rl *rateLimiter, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := rl.allow(keyFunc(r))
+			if !ok {
+				retry := int(math.Ceil(wait.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(retry))
+				replyError(w, r, http.StatusTooManyRequests, "Too many requests, retry later.")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKeyFunc returns the function identifying the client of a request,
+// either by its IP address or by its auth token
+func clientKeyFunc(by string) (func(*http.Request) string, error) {
+	switch by {
+	case "ip":
+		return clientIP, nil
+	case "token":
+		return func(r *http.Request) string {
+			if token := r.Header.Get("Authorization"); token != "" {
+				return "token:" + token
+			}
+			return clientIP(r)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown rate limit key %q", ErrInvalidData, by)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expLimit rateLimit
+		expError error
+	}{
+		{name: "RateAndBurst", input: "2:5", expLimit: rateLimit{Rate: 2, Burst: 5}},
+		{name: "RateOnly", input: "0.5", expLimit: rateLimit{Rate: 0.5, Burst: 1}},
+		{name: "Disabled", input: "0", expLimit: rateLimit{Rate: 0, Burst: 1}},
+		{name: "InvalidRate", input: "fast", expError: ErrInvalidData},
+		{name: "InvalidBurst", input: "1:0", expError: ErrInvalidData},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := parseRateLimit(tc.input)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Fatalf("Expected error %q, got %q", tc.expError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != tc.expLimit {
+				t.Errorf("Expected %v, got %v", tc.expLimit, l)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	rl := newRateLimiter(rateLimit{Rate: 1, Burst: 2})
+	rl.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := rl.allow("a"); !ok {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+
+	ok, wait := rl.allow("a")
+	if ok {
+		t.Fatal("Expected request to be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("Expected wait %s, got %s", time.Second, wait)
+	}
+
+	if ok, _ := rl.allow("b"); !ok {
+		t.Error("Expected other client to be allowed")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := rl.allow("a"); !ok {
+		t.Error("Expected request to be allowed after refill")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	cfg := serverConfig{
+		maxBodyBytes:  64,
+		maxTaskLength: 20,
+		routeLimits:   routeLimits{"GET /todo": {Rate: 0.01, Burst: 1}},
+	}
+	serverUrl, cleanup := setupTestServerConfig(t, cfg)
+	defer cleanup()
+
+	t.Run("BodyTooLarge", func(t *testing.T) {
+		body := `{"task":"` + strings.Repeat("a", 100) + `"}`
+		r, err := http.Post(serverUrl+"/todo", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expect status %d, got %d", http.StatusRequestEntityTooLarge, r.StatusCode)
+		}
+	})
+
+	t.Run("FormTooLarge", func(t *testing.T) {
+		body := "task=" + strings.Repeat("a", 100)
+		r, err := http.Post(serverUrl+"/ui/todo", "application/x-www-form-urlencoded", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expect status %d, got %d", http.StatusRequestEntityTooLarge, r.StatusCode)
+		}
+	})
+
+	t.Run("GraphQLTooLarge", func(t *testing.T) {
+		body := `{"query":"{ todos { task } }", "operationName": "` + strings.Repeat("a", 100) + `"}`
+		r, err := http.Post(serverUrl+"/graphql", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expect status %d, got %d", http.StatusRequestEntityTooLarge, r.StatusCode)
+		}
+	})
+
+	t.Run("TaskTooLong", func(t *testing.T) {
+		body := `{"task":"` + strings.Repeat("a", 21) + `"}`
+		r, err := http.Post(serverUrl+"/todo", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expect status %d, got %d", http.StatusBadRequest, r.StatusCode)
+		}
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		r, err := http.Get(serverUrl + "/todo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+
+		r, err = http.Get(serverUrl + "/todo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		if r.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expect status %d, got %d", http.StatusTooManyRequests, r.StatusCode)
+		}
+		if r.Header.Get("Retry-After") != "100" {
+			t.Errorf("Expect Retry-After %q, got %q", "100", r.Header.Get("Retry-After"))
+		}
+	})
+}
+
+func TestDefaultLimitShared(t *testing.T) {
+	cfg := serverConfig{rateLimit: rateLimit{Rate: 0.01, Burst: 1}}
+	ts := newTestServer(t, newTodoStore(filepath.Join(t.TempDir(), "todo.json")), cfg)
+	defer ts.Close()
+
+	// The routes without a limit of their own take from the same bucket
+	for _, tc := range []struct {
+		path      string
+		expStatus int
+	}{
+		{path: "/todo", expStatus: http.StatusOK},
+		{path: "/todo/due", expStatus: http.StatusTooManyRequests},
+	} {
+		r, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		if r.StatusCode != tc.expStatus {
+			t.Errorf("Expect status %d for %s, got %d", tc.expStatus, tc.path, r.StatusCode)
+		}
+	}
+}
+
+func TestUnknownRouteLimit(t *testing.T) {
+	cfg := serverConfig{routeLimits: routeLimits{"GET /todo": {Rate: 1, Burst: 1}, "GET /todos": {Rate: 1, Burst: 1}}}
+	_, err := newMux(newTodoStore(filepath.Join(t.TempDir(), "todo.json")), cfg)
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("Expected error %q, got %q", ErrInvalidData, err)
+	}
+	if !strings.Contains(err.Error(), `"GET /todos"`) {
+		t.Errorf("Expected the unknown route in the error, got %q", err)
+	}
+}
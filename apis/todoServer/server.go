@@ -3,111 +3,217 @@ package main
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
-	"sync"
+	"strings"
 	"todo"
 )
 
-func newMux(todoFile string) http.Handler {
+// serverConfig holds the limits enforced by the todo API. Zero values disable the matching limit
+type serverConfig struct {
+	maxBodyBytes  int64
+	maxTaskLength int
+	// rateLimit applies to every route without an entry in routeLimits
+	rateLimit   rateLimit
+	routeLimits routeLimits
+	// clientKey identifies the client a rate limit applies to, by IP when nil
+	clientKey func(*http.Request) string
+}
+
+// newMux returns the handler of the todo routes, or an error when a route
+// limit names no route
+func newMux(store *todoStore, cfg serverConfig) (http.Handler, error) {
 	m := http.NewServeMux()
-	mu := &sync.Mutex{}
-	list := &todo.List{}
+	mu, list, todoFile := &store.Mutex, store.list, store.todoFile
+	csrf := newCSRFProtector()
+	limiter := newRouteLimiter(cfg)
+
+	handle := func(pattern string, h http.HandlerFunc) {
+		if !strings.HasPrefix(pattern, http.MethodGet+" ") {
+			// Let the gRPC watchers know the list may have changed
+			next := h
+			h = func(w http.ResponseWriter, r *http.Request) {
+				next(w, r)
+				store.notify()
+			}
+		}
+		m.Handle(pattern, limiter.limit(pattern, h))
+	}
 
-	m.HandleFunc("GET /", rootHandler)
-	m.HandleFunc("GET /todo", func(w http.ResponseWriter, r *http.Request) {
+	// Web UI
+	handle("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
 		mu.Lock()
 		defer mu.Unlock()
-		getTodoRouter(w, r, list, todoFile)
+		webIndexHandler(w, r, list, todoFile, csrf)
 	})
-	m.HandleFunc("GET /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
-		id, err := validateID(r.PathValue("id"), list)
-		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				replyError(w, r, http.StatusNotFound, err.Error())
-				return
-			}
-			replyError(w, r, http.StatusBadRequest, err.Error())
+	handle("POST /ui/todo", csrf.protect(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		webAddHandler(w, r, list, todoFile, csrf, cfg.maxTaskLength)
+	}))
+	handle("POST /ui/todo/{id}/complete", csrf.protect(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id, ok := itemID(w, r, list, todoFile)
+		if !ok {
+			return
+		}
+		webCompleteHandler(w, r, list, id, todoFile)
+	}))
+	handle("POST /ui/todo/{id}/delete", csrf.protect(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id, ok := itemID(w, r, list, todoFile)
+		if !ok {
 			return
 		}
+		webDeleteHandler(w, r, list, id, todoFile)
+	}))
+
+	// GraphQL API
+	gqlEnv := &graphqlEnv{list: list, todoFile: todoFile, maxTaskLength: cfg.maxTaskLength}
+	handle("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		graphqlHandler(w, r, gqlEnv)
+	})
 
+	// JSON API
+	handle("GET /todo", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		getTodoRouter(w, r, list, todoFile)
+	})
+	handle("GET /todo/due", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		dueTodoRouter(w, r, list, todoFile)
+	})
+	handle("GET /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
 		mu.Lock()
 		defer mu.Unlock()
+		id, ok := itemID(w, r, list, todoFile)
+		if !ok {
+			return
+		}
 		getSingleTodoRouter(w, r, list, id)
 	})
 
 	// POST
-	m.HandleFunc("POST /todo", func(w http.ResponseWriter, r *http.Request) {
+	handle("POST /todo", func(w http.ResponseWriter, r *http.Request) {
 		mu.Lock()
 		defer mu.Unlock()
-		addTodoRouter(w, r, list, todoFile)
+		if !reload(w, r, list, todoFile) {
+			return
+		}
+		addTodoRouter(w, r, list, todoFile, cfg.maxTaskLength)
 	})
 
 	// UPDATE COMPLETE
-	m.HandleFunc("PATCH /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
-		id, err := validateID(r.PathValue("id"), list)
-		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				replyError(w, r, http.StatusNotFound, err.Error())
-				return
-			}
-			replyError(w, r, http.StatusBadRequest, err.Error())
+	handle("PATCH /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id, ok := itemID(w, r, list, todoFile)
+		if !ok {
 			return
 		}
 		// Check if query complete
 		q := r.URL.Query()
-		_, ok := q["complete"]
-		if !ok {
+		if _, ok := q["complete"]; !ok {
 			replyError(w, r, http.StatusBadRequest, "Missing require query complete.")
+			return
 		}
 
-		mu.Lock()
-		defer mu.Unlock()
 		pacthHandler(w, r, list, id, todoFile)
 	})
 
 	// DELETE
-	m.HandleFunc("DELETE /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
-		id, err := validateID(r.PathValue("id"), list)
-		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				replyError(w, r, http.StatusNotFound, err.Error())
-				return
-			}
-			replyError(w, r, http.StatusBadRequest, err.Error())
-			return
-		}
+	handle("DELETE /todo/{id}", func(w http.ResponseWriter, r *http.Request) {
 		mu.Lock()
 		defer mu.Unlock()
+		id, ok := itemID(w, r, list, todoFile)
+		if !ok {
+			return
+		}
 		deleteTodoHandler(w, r, list, id, todoFile)
 	})
 
-	// Dirty way to add Logging middleware because it quite hard to see
-	return loggingMiddleware(m)
+	if err := limiter.unknown(); err != nil {
+		return nil, err
+	}
+	return chain(m, requestIDMiddleware, loggingMiddleware, maxBytesMiddleware(cfg.maxBodyBytes)), nil
+}
+
+// reload reads the list from the file, which another process may have
+// written, before a route changes it, replying the error when it fails. The
+// caller must hold the lock
+func reload(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string) bool {
+	if err := list.Get(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
+
+// itemID reloads the list and validates the id of the request, replying the
+// error when invalid. The caller must hold the lock
+func itemID(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string) (int, bool) {
+	if !reload(w, r, list, todoFile) {
+		return 0, false
+	}
+
+	id, err := validateID(r.PathValue("id"), list)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			replyError(w, r, http.StatusNotFound, err.Error())
+			return 0, false
+		}
+		replyError(w, r, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// replyBodyError replies the error reading the request body, 413 Request
+// Entity Too Large when the body exceeds the size limit or else 400
+func replyBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		replyError(w, r, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	replyError(w, r, http.StatusBadRequest, err.Error())
 }
 
 func replyPlainText(w http.ResponseWriter, r *http.Request, status int, content string) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(status)
 	if _, err := w.Write([]byte(content)); err != nil {
-		log.Printf("error writing response: %v", err)
+		slog.ErrorContext(r.Context(), "error writing response", "request_id", requestID(r.Context()), "error", err)
 		replyError(w, r, http.StatusInternalServerError, err.Error())
 	}
 }
 
-func replyJSONContent(w http.ResponseWriter, r *http.Request, status int, resp *todoResponse) {
+func replyJSONContent(w http.ResponseWriter, r *http.Request, status int, resp json.Marshaler) {
 	body, err := json.Marshal(resp)
 	if err != nil {
 		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if _, err := w.Write(body); err != nil {
-		log.Printf("error writing response: %v", err)
+		slog.ErrorContext(r.Context(), "error writing response", "request_id", requestID(r.Context()), "error", err)
 	}
 }
 
 func replyError(w http.ResponseWriter, r *http.Request, status int, message string) {
-	log.Printf("%s %s: Error: %d %s", r.URL, r.Method, status, message)
+	slog.ErrorContext(r.Context(), "request error",
+		"request_id", requestID(r.Context()),
+		"method", r.Method,
+		"uri", r.URL.RequestURI(),
+		"status", status,
+		"error", message,
+	)
 	http.Error(w, message, status)
 }
@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 	"todo"
 )
 
@@ -28,7 +30,6 @@ func TestGetTodo(t *testing.T) {
 		expItems   int
 		expReponse string
 	}{
-		{name: "GetRoot", route: "/", expStatus: http.StatusOK, expReponse: "hello there you hit the api"},
 		{name: "GetAll", route: "/todo", expStatus: http.StatusOK, expItems: 2, expReponse: "Task number 1."},
 		{name: "GetSingle", route: "/todo/2", expStatus: http.StatusOK, expItems: 1, expReponse: "Task number 2."},
 		{name: "NotFoundRoute", route: "/invalid/todo", expStatus: http.StatusNotFound, expReponse: "404 page not found\n"},
@@ -106,6 +107,32 @@ func TestAddTodo(t *testing.T) {
 	}
 }
 
+// TestAddTodoRestart posts to a new server on the file of a previous one, like
+// the todoClient replaying its queued adds first once the server restarted
+func TestAddTodoRestart(t *testing.T) {
+	todoFile := filepath.Join(t.TempDir(), "todo.json")
+	for _, task := range []string{"Task number 1.", "Task number 2."} {
+		ts := newTestServer(t, newTodoStore(todoFile), serverConfig{})
+		r, err := http.Post(ts.URL+"/todo", "application/json", strings.NewReader(`{"task": "`+task+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		ts.Close()
+		if r.StatusCode != http.StatusCreated {
+			t.Fatalf("Expect status %d, got %d", http.StatusCreated, r.StatusCode)
+		}
+	}
+
+	l := &todo.List{}
+	if err := l.Get(todoFile); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Items) != 2 || l.Items[0].Task != "Task number 1." || l.Items[1].Task != "Task number 2." {
+		t.Errorf("Expect both tasks kept, got %v", l.Items)
+	}
+}
+
 func TestDeleteTodo(t *testing.T) {
 	serverUrl, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -218,15 +245,126 @@ func TestCompleteTodo(t *testing.T) {
 	})
 }
 
-func setupTestServer(t *testing.T) (string, func()) {
-	t.Helper()
+func TestDueTodo(t *testing.T) {
+	serverUrl, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	testCases := []struct {
+		name      string
+		body      string
+		expStatus int
+	}{
+		{name: "AddOverdue", body: `{"task": "Overdue task", "due": "` + yesterday + `"}`, expStatus: http.StatusCreated},
+		{name: "AddLater", body: `{"task": "Later task", "due": "2999-01-01T00:00:00Z"}`, expStatus: http.StatusCreated},
+		{name: "InvalidDue", body: `{"task": "Bad task", "due": "someday"}`, expStatus: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.Post(serverUrl+"/todo", "application/json", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Body.Close()
+			if r.StatusCode != tc.expStatus {
+				t.Errorf("Expect status %d, got %d", tc.expStatus, r.StatusCode)
+			}
+		})
+	}
+
+	r, err := http.Get(serverUrl + "/todo/due")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+	}
+
+	var resp todo.DueReport
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Overdue) != 1 || resp.Overdue[0].ID != 3 || resp.Overdue[0].Task != "Overdue task" {
+		t.Errorf("Expect task 3 to be overdue, got %v", resp.Overdue)
+	}
+	if len(resp.Today) != 0 || len(resp.Week) != 0 {
+		t.Errorf("Expect nothing else due, got %v and %v", resp.Today, resp.Week)
+	}
+}
 
-	var tempFile, err = os.CreateTemp(t.TempDir(), "")
+func TestRoutesReload(t *testing.T) {
+	tempFile, err := os.CreateTemp(t.TempDir(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	ts := httptest.NewServer(newMux(tempFile.Name()))
+	// The file exists before the server starts, with an empty list in memory
+	saved := &todo.List{}
+	saved.Add("Task number 1.")
+	saved.Add("Task number 2.")
+	if err := saved.Save(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+	ts := newTestServer(t, newTodoStore(tempFile.Name()), serverConfig{})
+	defer ts.Close()
+
+	testCases := []struct {
+		name      string
+		method    string
+		endpoint  string
+		body      string
+		expStatus int
+	}{
+		{name: "Post", method: http.MethodPost, endpoint: "/todo", body: `{"task": "Task number 3."}`, expStatus: http.StatusCreated},
+		{name: "Get", method: http.MethodGet, endpoint: "/todo/2", expStatus: http.StatusOK},
+		{name: "PatchMissingQuery", method: http.MethodPatch, endpoint: "/todo/2", expStatus: http.StatusBadRequest},
+		{name: "Delete", method: http.MethodDelete, endpoint: "/todo/1", expStatus: http.StatusNoContent},
+		{name: "NotFound", method: http.MethodPatch, endpoint: "/todo/3?complete", expStatus: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, ts.URL+tc.endpoint, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Body.Close()
+			if r.StatusCode != tc.expStatus {
+				t.Errorf("Expect status %d, got %d", tc.expStatus, r.StatusCode)
+			}
+		})
+	}
+
+	l := &todo.List{}
+	if err := l.Get(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Items) != 2 || l.Items[0].Task != "Task number 2." || l.Items[1].Task != "Task number 3." {
+		t.Fatalf("Expect %q and %q left, got %v", "Task number 2.", "Task number 3.", l.Items)
+	}
+	// The rejected PATCH must not complete the item
+	if l.Items[0].Done {
+		t.Errorf("Expect %q not completed", l.Items[0].Task)
+	}
+}
+
+func setupTestServer(t *testing.T) (string, func()) {
+	t.Helper()
+	return setupTestServerConfig(t, serverConfig{})
+}
+
+func setupTestServerConfig(t *testing.T, cfg serverConfig) (string, func()) {
+	t.Helper()
+
+	// The routes reload the list before changing it, the file must be
+	// missing or hold a list
+	todoFile := filepath.Join(t.TempDir(), "todo.json")
+
+	ts := newTestServer(t, newTodoStore(todoFile), cfg)
 	for i := 1; i < 3; i++ {
 		var body bytes.Buffer
 		taskName := fmt.Sprintf("Task number %d.", i)
@@ -253,3 +391,13 @@ func setupTestServer(t *testing.T) (string, func()) {
 		ts.Close()
 	}
 }
+
+// newTestServer serves the routes of the store with the config
+func newTestServer(t *testing.T, store *todoStore, cfg serverConfig) *httptest.Server {
+	t.Helper()
+	h, err := newMux(store, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(h)
+}
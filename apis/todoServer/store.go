@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sync"
+	"todo"
+)
+
+// todoStore holds the todo list and its file shared by every API the server exposes.
+// Callers must hold the lock while using list
+type todoStore struct {
+	sync.Mutex
+	list     *todo.List
+	todoFile string
+
+	watchMu  sync.Mutex
+	watchers map[chan struct{}]struct{}
+}
+
+func newTodoStore(todoFile string) *todoStore {
+	return &todoStore{
+		list:     &todo.List{},
+		todoFile: todoFile,
+		watchers: map[chan struct{}]struct{}{},
+	}
+}
+
+// watch returns a channel signaled after a request may have changed the list,
+// and a function to stop watching
+func (s *todoStore) watch() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	s.watchMu.Lock()
+	s.watchers[ch] = struct{}{}
+	s.watchMu.Unlock()
+
+	return ch, func() {
+		s.watchMu.Lock()
+		delete(s.watchers, ch)
+		s.watchMu.Unlock()
+	}
+}
+
+// notify signals the watchers without blocking, a pending signal already covers the change
+func (s *todoStore) notify() {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -30,3 +30,20 @@ func (r *todoResponse) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(resp)
 }
+
+// dueResponse is the summary of the pending items by due date
+type dueResponse struct {
+	Report todo.DueReport
+}
+
+func (r *dueResponse) MarshalJSON() ([]byte, error) {
+	resp := struct {
+		todo.DueReport
+		Date int64 `json:"date"`
+	}{
+		DueReport: r.Report,
+		Date:      time.Now().Unix(),
+	}
+
+	return json.Marshal(resp)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+	"todo"
+)
+
+const (
+	csrfCookie = "todo_csrf"
+	csrfField  = "csrf_token"
+)
+
+//go:embed templates/*.html.tmpl
+var templateFS embed.FS
+
+var webTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html.tmpl"))
+
+type webItem struct {
+	ID        int
+	Task      string
+	Done      bool
+	CreatedAt time.Time
+	Due       time.Time
+}
+
+type webPage struct {
+	Items     []webItem
+	CSRFToken string
+	Error     string
+}
+
+// csrfProtector issues and verifies CSRF tokens for the web UI forms.
+// Each browser gets a random nonce in a cookie and the forms carry the
+// HMAC of that nonce, so a third party site can't forge a valid token
+type csrfProtector struct {
+	secret []byte
+}
+
+func newCSRFProtector() *csrfProtector {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		// crypto/rand only fails when the OS has no entropy source, nothing to recover from
+		panic(err)
+	}
+	return &csrfProtector{secret: secret}
+}
+
+func (c *csrfProtector) sign(nonce string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// token returns the CSRF token of the request, setting a new nonce cookie if needed
+func (c *csrfProtector) token(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && cookie.Value != "" {
+		return c.sign(cookie.Value)
+	}
+
+	nonce := newRequestID()
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    nonce,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return c.sign(nonce)
+}
+
+// protect rejects the form posts without a valid CSRF token
+func (c *csrfProtector) protect(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			replyBodyError(w, r, err)
+			return
+		}
+		cookie, err := r.Cookie(csrfCookie)
+		if err != nil || cookie.Value == "" {
+			replyError(w, r, http.StatusForbidden, "Missing CSRF cookie.")
+			return
+		}
+
+		expected := c.sign(cookie.Value)
+		if !hmac.Equal([]byte(expected), []byte(r.PostFormValue(csrfField))) {
+			replyError(w, r, http.StatusForbidden, "Invalid CSRF token.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func webIndexHandler(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string, csrf *csrfProtector) {
+	if err := list.Get(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	replyHTML(w, r, http.StatusOK, list, csrf.token(w, r), "")
+}
+
+func webAddHandler(w http.ResponseWriter, r *http.Request, list *todo.List, todoFile string, csrf *csrfProtector, maxTaskLength int) {
+	// Another process may have written the file since the page was served
+	if err := list.Get(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	task := r.PostFormValue("task")
+	if task == "" {
+		replyHTML(w, r, http.StatusBadRequest, list, csrf.token(w, r), "Task cannot be blank.")
+		return
+	}
+	if err := validateTask(task, maxTaskLength); err != nil {
+		replyHTML(w, r, http.StatusBadRequest, list, csrf.token(w, r), err.Error())
+		return
+	}
+
+	var due time.Time
+	if d := r.PostFormValue("due"); d != "" {
+		var err error
+		if due, err = todo.ParseDue(d, time.Local); err != nil {
+			replyHTML(w, r, http.StatusBadRequest, list, csrf.token(w, r), err.Error())
+			return
+		}
+	}
+
+	list.Add(task)
+	if err := list.SetDue(len(list.Items), due); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := list.Save(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func webCompleteHandler(w http.ResponseWriter, r *http.Request, list *todo.List, id int, todoFile string) {
+	if err := list.Complete(id); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := list.Save(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func webDeleteHandler(w http.ResponseWriter, r *http.Request, list *todo.List, id int, todoFile string) {
+	if err := list.Delete(id); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := list.Save(todoFile); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func replyHTML(w http.ResponseWriter, r *http.Request, status int, list *todo.List, csrfToken, errMsg string) {
+	page := webPage{
+		CSRFToken: csrfToken,
+		Error:     errMsg,
+	}
+	for k, item := range list.Items {
+		page.Items = append(page.Items, webItem{
+			ID:        k + 1,
+			Task:      item.Task,
+			Done:      item.Done,
+			CreatedAt: item.CreatedAt,
+			Due:       item.Due,
+		})
+	}
+
+	var body bytes.Buffer
+	if err := webTemplates.ExecuteTemplate(&body, "index.html.tmpl", page); err != nil {
+		replyError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := body.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "error writing response", "request_id", requestID(r.Context()), "error", err)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"todo"
+)
+
+var csrfRe = regexp.MustCompile(`name="csrf_token" value="([0-9a-f]+)"`)
+
+func TestWebUI(t *testing.T) {
+	serverUrl, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+
+	getPage := func(t *testing.T) (string, string) {
+		t.Helper()
+		r, err := client.Get(serverUrl + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+		if !strings.Contains(r.Header.Get("Content-Type"), "text/html") {
+			t.Fatalf("Expect HTML content, got %q", r.Header.Get("Content-Type"))
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := csrfRe.FindStringSubmatch(string(body))
+		if m == nil {
+			t.Fatal("Expect page to contain a CSRF token")
+		}
+		return string(body), m[1]
+	}
+
+	post := func(t *testing.T, route string, form url.Values) *http.Response {
+		t.Helper()
+		r, err := client.PostForm(serverUrl+route, form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		return r
+	}
+
+	t.Run("List", func(t *testing.T) {
+		page, _ := getPage(t)
+		for _, task := range []string{"Task number 1.", "Task number 2."} {
+			if !strings.Contains(page, task) {
+				t.Errorf("Expect page to contain %q", task)
+			}
+		}
+	})
+
+	t.Run("MissingCSRF", func(t *testing.T) {
+		r := post(t, "/ui/todo", url.Values{"task": {"forged"}})
+		if r.StatusCode != http.StatusForbidden {
+			t.Errorf("Expect status %d, got %d", http.StatusForbidden, r.StatusCode)
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		_, token := getPage(t)
+		r := post(t, "/ui/todo", url.Values{"task": {"Task from <web>"}, "csrf_token": {token}})
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+		page, _ := getPage(t)
+		if !strings.Contains(page, "Task from &lt;web&gt;") {
+			t.Error("Expect page to contain the escaped new task")
+		}
+	})
+
+	t.Run("AddDue", func(t *testing.T) {
+		_, token := getPage(t)
+		r := post(t, "/ui/todo", url.Values{"task": {"Task due"}, "due": {"2999-01-02"}, "csrf_token": {token}})
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+		page, _ := getPage(t)
+		if !strings.Contains(page, "due Jan/02 @23:59") {
+			t.Error("Expect page to contain the due date")
+		}
+
+		_, token = getPage(t)
+		r = post(t, "/ui/todo", url.Values{"task": {"Task due"}, "due": {"someday"}, "csrf_token": {token}})
+		if r.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expect status %d, got %d", http.StatusBadRequest, r.StatusCode)
+		}
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		_, token := getPage(t)
+		r := post(t, "/ui/todo/1/complete", url.Values{"csrf_token": {token}})
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+		page, _ := getPage(t)
+		if !strings.Contains(page, `class="done">Task number 1.`) {
+			t.Error("Expect task 1 to be completed")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, token := getPage(t)
+		r := post(t, "/ui/todo/2/delete", url.Values{"csrf_token": {token}})
+		if r.StatusCode != http.StatusOK {
+			t.Fatalf("Expect status %d, got %d", http.StatusOK, r.StatusCode)
+		}
+		page, _ := getPage(t)
+		if strings.Contains(page, "Task number 2.") {
+			t.Error("Expect task 2 to be deleted")
+		}
+	})
+}
+
+func TestWebAddReload(t *testing.T) {
+	todoFile := filepath.Join(t.TempDir(), "todo.json")
+	ts := newTestServer(t, newTodoStore(todoFile), serverConfig{})
+	defer ts.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+	r, err := client.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := csrfRe.FindStringSubmatch(string(body))[1]
+
+	// Another process adds an item once the page is served
+	l := &todo.List{}
+	l.Add("Task from another process")
+	if err := l.Save(todoFile); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		r, err := client.PostForm(ts.URL+"/ui/todo", url.Values{"csrf_token": {token}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expect status %d, got %d", http.StatusBadRequest, r.StatusCode)
+		}
+		if !strings.Contains(string(body), "Task from another process") {
+			t.Error("Expect the page to list the task from another process")
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		r, err := client.PostForm(ts.URL+"/ui/todo", url.Values{"task": {"Task from web"}, "csrf_token": {token}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		if err := l.Get(todoFile); err != nil {
+			t.Fatal(err)
+		}
+		if len(l.Items) != 2 || l.Items[0].Task != "Task from another process" || l.Items[1].Task != "Task from web" {
+			t.Errorf("Expect both tasks kept, got %v", l.Items)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+// Package todocontract describes the todo REST API as request and response
+// pairs shared by todoServer and todoClient. The server tests replay each
+// interaction against the real routes and the client tests against the client,
+// so a change of the wire format on either side breaks both builds.
+package todocontract
+
+import (
+	"net/http"
+	"time"
+)
+
+// Item is a todo item as stored by the server before an interaction
+type Item struct {
+	Task        string
+	Done        bool
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	Due         time.Time
+}
+
+// Operations of the client matching the interactions
+const (
+	OpList     = "list"
+	OpGet      = "get"
+	OpAdd      = "add"
+	OpComplete = "complete"
+	OpDelete   = "delete"
+	OpDue      = "due"
+)
+
+// Request is the request sent by the client
+type Request struct {
+	Method      string
+	Path        string
+	ContentType string
+	// Body is compared as JSON when ContentType is application/json
+	Body string
+}
+
+// Response is the reply of the server
+type Response struct {
+	Status      int
+	ContentType string
+	// Body is compared as JSON when ContentType is application/json
+	Body string
+	// Volatile lists the JSON fields whose value changes on every response,
+	// like the date. They must be present but their value isn't compared
+	Volatile []string
+}
+
+// Interaction is a request to a server holding Items and its expected response.
+// Op, ID and Task describe the client call sending the request
+type Interaction struct {
+	Name  string
+	Items []Item
+	Op    string
+	ID    int
+	Task  string
+	// Due is the due date of the added task, zero when none
+	Due time.Time
+	Request
+	Response
+}
+
+var created = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+// Items is the list held by the server in most interactions
+var Items = []Item{
+	{Task: "Task number 1.", CreatedAt: created},
+	{Task: "Task number 2.", Done: true, CreatedAt: created.Add(time.Hour), CompletedAt: created.Add(2 * time.Hour)},
+}
+
+// DueItems is the list held by the server in the due summary interaction,
+// the first item being overdue whatever the current date
+var DueItems = []Item{
+	{Task: "Task number 1.", CreatedAt: created, Due: created.Add(24 * time.Hour)},
+	{Task: "Task number 2.", Done: true, CreatedAt: created.Add(time.Hour), CompletedAt: created.Add(2 * time.Hour), Due: created},
+	{Task: "Task number 3.", CreatedAt: created.Add(2 * time.Hour)},
+}
+
+const (
+	jsonType = "application/json"
+	textType = "text/plain"
+)
+
+// Interactions lists the contract of each endpoint
+var Interactions = []Interaction{
+	{
+		Name:    "ListAll",
+		Items:   Items,
+		Op:      OpList,
+		Request: Request{Method: http.MethodGet, Path: "/todo"},
+		Response: Response{Status: http.StatusOK, ContentType: jsonType, Body: `{
+			"results": [
+				{"Task": "Task number 1.", "Done": false, "CreatedAt": "2024-03-01T09:30:00Z", "CompletedAt": "0001-01-01T00:00:00Z"},
+				{"Task": "Task number 2.", "Done": true, "CreatedAt": "2024-03-01T10:30:00Z", "CompletedAt": "2024-03-01T11:30:00Z"}
+			],
+			"date": 1709285400,
+			"totalResults": 2
+		}`, Volatile: []string{"date"}},
+	},
+	{
+		Name:    "ListEmpty",
+		Op:      OpList,
+		Request: Request{Method: http.MethodGet, Path: "/todo"},
+		Response: Response{Status: http.StatusOK, ContentType: jsonType, Body: `{
+			"results": null,
+			"date": 1709285400,
+			"totalResults": 0
+		}`, Volatile: []string{"date"}},
+	},
+	{
+		Name:    "Get",
+		Items:   Items,
+		Op:      OpGet,
+		ID:      2,
+		Request: Request{Method: http.MethodGet, Path: "/todo/2"},
+		Response: Response{Status: http.StatusOK, ContentType: jsonType, Body: `{
+			"results": [
+				{"Task": "Task number 2.", "Done": true, "CreatedAt": "2024-03-01T10:30:00Z", "CompletedAt": "2024-03-01T11:30:00Z"}
+			],
+			"date": 1709285400,
+			"totalResults": 1
+		}`, Volatile: []string{"date"}},
+	},
+	{
+		Name:     "GetNotFound",
+		Items:    Items,
+		Op:       OpGet,
+		ID:       3,
+		Request:  Request{Method: http.MethodGet, Path: "/todo/3"},
+		Response: Response{Status: http.StatusNotFound, ContentType: textType, Body: "not found Id not found: 3\n"},
+	},
+	{
+		Name:     "GetInvalidID",
+		Items:    Items,
+		Op:       OpGet,
+		ID:       0,
+		Request:  Request{Method: http.MethodGet, Path: "/todo/0"},
+		Response: Response{Status: http.StatusBadRequest, ContentType: textType, Body: "invalid data: Invalid ID: less than 1\n"},
+	},
+	{
+		Name:     "Add",
+		Items:    Items,
+		Op:       OpAdd,
+		Task:     "Task number 3.",
+		Request:  Request{Method: http.MethodPost, Path: "/todo", ContentType: jsonType, Body: `{"task": "Task number 3."}`},
+		Response: Response{Status: http.StatusCreated, ContentType: textType},
+	},
+	{
+		Name:     "AddDue",
+		Items:    Items,
+		Op:       OpAdd,
+		Task:     "Task number 3.",
+		Due:      time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC),
+		Request:  Request{Method: http.MethodPost, Path: "/todo", ContentType: jsonType, Body: `{"task": "Task number 3.", "due": "2024-03-08T17:00:00Z"}`},
+		Response: Response{Status: http.StatusCreated, ContentType: textType},
+	},
+	{
+		Name:    "Due",
+		Items:   DueItems,
+		Op:      OpDue,
+		Request: Request{Method: http.MethodGet, Path: "/todo/due"},
+		Response: Response{Status: http.StatusOK, ContentType: jsonType, Body: `{
+			"overdue": [
+				{"ID": 1, "Task": "Task number 1.", "Done": false, "CreatedAt": "2024-03-01T09:30:00Z", "CompletedAt": "0001-01-01T00:00:00Z", "Due": "2024-03-02T09:30:00Z"}
+			],
+			"today": [],
+			"week": [],
+			"date": 1709285400
+		}`, Volatile: []string{"date"}},
+	},
+	{
+		Name:     "Complete",
+		Items:    Items,
+		Op:       OpComplete,
+		ID:       1,
+		Request:  Request{Method: http.MethodPatch, Path: "/todo/1?complete"},
+		Response: Response{Status: http.StatusNoContent},
+	},
+	{
+		Name:     "CompleteNotFound",
+		Items:    Items,
+		Op:       OpComplete,
+		ID:       5,
+		Request:  Request{Method: http.MethodPatch, Path: "/todo/5?complete"},
+		Response: Response{Status: http.StatusNotFound, ContentType: textType, Body: "not found Id not found: 5\n"},
+	},
+	{
+		Name:     "Delete",
+		Items:    Items,
+		Op:       OpDelete,
+		ID:       1,
+		Request:  Request{Method: http.MethodDelete, Path: "/todo/1"},
+		Response: Response{Status: http.StatusNoContent},
+	},
+}
@@ -0,0 +1,3 @@
+module apis/todocontract
+
+go 1.25.0
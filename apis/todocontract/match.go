@@ -0,0 +1,100 @@
+package todocontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"slices"
+)
+
+// IsJSON reports whether the content type is JSON
+func IsJSON(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+	return err == nil && t == jsonType
+}
+
+// Match compares a response body with the expected one
+func (r Response) Match(actual []byte) error {
+	return MatchBody(r.ContentType, r.Body, actual, r.Volatile...)
+}
+
+// MatchBody compares a body with the expected one of the contract, as JSON
+// for the JSON content type and as text otherwise
+func MatchBody(contentType, expected string, actual []byte, volatile ...string) error {
+	if !IsJSON(contentType) {
+		if string(actual) != expected {
+			return fmt.Errorf("expected body %q, got %q", expected, actual)
+		}
+		return nil
+	}
+	return MatchJSON(expected, actual, volatile...)
+}
+
+// MatchJSON compares two JSON documents. The volatile fields, given as dot
+// separated paths from the root like "date", only need to be present
+func MatchJSON(expected string, actual []byte, volatile ...string) error {
+	var exp, got any
+	if err := json.Unmarshal([]byte(expected), &exp); err != nil {
+		return fmt.Errorf("invalid expected JSON: %w", err)
+	}
+	if err := json.Unmarshal(actual, &got); err != nil {
+		return fmt.Errorf("invalid JSON %q: %w", actual, err)
+	}
+
+	ignore := map[string]bool{}
+	for _, v := range volatile {
+		ignore["$."+v] = true
+	}
+	return match("$", exp, got, ignore)
+}
+
+func match(path string, exp, got any, ignore map[string]bool) error {
+	if ignore[path] {
+		return nil
+	}
+
+	switch e := exp.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %v", path, got)
+		}
+		for k := range g {
+			if _, ok := e[k]; !ok {
+				return fmt.Errorf("%s: unexpected field %q", path, k)
+			}
+		}
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			v, ok := g[k]
+			if !ok {
+				return fmt.Errorf("%s: missing field %q", path, k)
+			}
+			if err := match(path+"."+k, e[k], v, ignore); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(e) {
+			return fmt.Errorf("%s: expected %d elements, got %v", path, len(e), got)
+		}
+		for k := range e {
+			if err := match(fmt.Sprintf("%s[%d]", path, k), e[k], g[k], ignore); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !reflect.DeepEqual(exp, got) {
+		return fmt.Errorf("%s: expected %v, got %v", path, exp, got)
+	}
+	return nil
+}
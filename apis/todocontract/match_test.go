@@ -0,0 +1,34 @@
+package todocontract
+
+import "testing"
+
+func TestMatchJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		actual   string
+		volatile []string
+		expMatch bool
+	}{
+		{name: "Equal", expected: `{"a": [1, "x"], "b": true}`, actual: `{"b":true,"a":[1,"x"]}`, expMatch: true},
+		{name: "Volatile", expected: `{"date": 1}`, actual: `{"date": 1709285400}`, volatile: []string{"date"}, expMatch: true},
+		{name: "VolatileNested", expected: `{"a": {"b": 1}}`, actual: `{"a": {"b": 2}}`, volatile: []string{"a.b"}, expMatch: true},
+		{name: "VolatileMissing", expected: `{"date": 1}`, actual: `{}`, volatile: []string{"date"}},
+		{name: "Different", expected: `{"a": 1}`, actual: `{"a": 2}`},
+		{name: "ExtraField", expected: `{"a": 1}`, actual: `{"a": 1, "b": 2}`},
+		{name: "Length", expected: `[1]`, actual: `[1, 2]`},
+		{name: "Invalid", expected: `{}`, actual: `{`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MatchJSON(tc.expected, []byte(tc.actual), tc.volatile...)
+			if tc.expMatch && err != nil {
+				t.Errorf("Expect match, got %q", err)
+			}
+			if !tc.expMatch && err == nil {
+				t.Error("Expect mismatch, got match")
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package todopb holds the protobuf messages and gRPC stubs of the todo API
+// shared by todoServer and todoClient.
+package todopb
+
+//go:generate buf generate
@@ -9,6 +9,7 @@ import (
 	"github.com/mum4k/termdash"
 	"github.com/mum4k/termdash/terminal/tcell"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"haonguyen.tech/interactiveTools/pomo/notify"
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
 
@@ -21,14 +22,27 @@ type App struct {
 	size       image.Point
 }
 
-func New(config *pomodoro.IntervalConfig) (*App, error) {
+func New(config *pomodoro.IntervalConfig, n *notify.Notifier) (*App, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	redrawCh := make(chan bool)
 	errorCh := make(chan error)
 
+	var l *layout
 	quitter := func(k *terminalapi.Keyboard) { // Quit on pressing 'q'
+		if l.in.active() {
+			return
+		}
+		if k.Key == 'v' || k.Key == 'V' {
+			// Switch between the timer and the summary
+			errorCh <- l.toggle()
+			return
+		}
 		if k.Key == 'q' || k.Key == 'Q' {
+			// Keep the running interval to resume it next time
+			if err := pomodoro.Suspend(config); err != nil {
+				errorCh <- err
+			}
 			cancel() // Cancels the context, exiting the app
 		}
 	}
@@ -38,11 +52,11 @@ func New(config *pomodoro.IntervalConfig) (*App, error) {
 		return nil, err
 	}
 
-	c, err := newGrid(ctx, term, config, errorCh, redrawCh)
+	l, err = newGrid(ctx, term, config, n, errorCh, redrawCh)
 	if err != nil {
 		return nil, err
 	}
-	controller, err := termdash.NewController(term, c, termdash.KeyboardSubscriber(quitter))
+	controller, err := termdash.NewController(term, l.c, termdash.KeyboardSubscriber(quitter))
 	if err != nil {
 		return nil, err
 	}
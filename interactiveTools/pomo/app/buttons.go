@@ -2,22 +2,38 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/widgets/button"
+	"haonguyen.tech/interactiveTools/pomo/notify"
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
 
 type buttonSet struct {
-	btStart *button.Button
-	btPause *button.Button
+	btStart  *button.Button
+	btPause  *button.Button
+	btCancel *button.Button
+	btSkip   *button.Button
+	btReset  *button.Button
+	in       *input
 }
 
 // newButtons Display the buttons, also include callback when a button is pressing to control the UI
-func newButtons(ctx context.Context, config *pomodoro.IntervalConfig,
-	w *widgets, errorCh chan error, redrawCh chan<- bool,
+func newButtons(ctx context.Context, config *pomodoro.IntervalConfig, n *notify.Notifier,
+	w *widgets, s *summary, errorCh chan error, redrawCh chan<- bool,
 ) (*buttonSet, error) {
+	// Notify in the background, a failing notification only shows up in the message
+	notifyEvent := func(event string, i pomodoro.Interval) {
+		go func() {
+			if err := n.Notify(ctx, event, i); err != nil && ctx.Err() == nil {
+				w.updateWidgets(redrawCh, fmt.Sprintf("Notification failed: %s", err), "", "", []int{})
+			}
+		}()
+	}
+
 	// Trigger when "start" button is clicked
 	startInterval := func() {
 		i, err := pomodoro.GetInterval(config)
@@ -25,22 +41,33 @@ func newButtons(ctx context.Context, config *pomodoro.IntervalConfig,
 
 		start := func(i pomodoro.Interval) {
 			message := "Take a break"
-			if i.Category == pomodoro.CategoryPomodoro {
+			switch {
+			case i.Label != "":
+				message = fmt.Sprintf("Focus on %s", i.Label)
+			case i.Category == pomodoro.CategoryPomodoro:
 				message = "Focus on your task"
 			}
 
 			w.updateWidgets(redrawCh, message, i.Category, "", []int{})
+			notifyEvent(notify.EventStart, i)
 		}
 
 		periodic := func(i pomodoro.Interval) {
 			w.updateWidgets(redrawCh, "", "", fmt.Sprint(i.PlannedDuration-i.ActualDuration), []int{int(i.ActualDuration), int(i.PlannedDuration)})
 		}
 
-		end := func(pomodoro.Interval) {
+		end := func(i pomodoro.Interval) {
+			errorCh <- s.update()
 			w.updateWidgets(redrawCh, "Nothing running...", i.Category, "", []int{})
+			notifyEvent(notify.EventEnd, i)
+		}
+
+		countdown := func(next pomodoro.Interval, left time.Duration) {
+			w.updateWidgets(redrawCh, fmt.Sprintf("%s starts in %s, (c)ancel to stop", next.Category, left),
+				next.Category, fmt.Sprint(next.PlannedDuration), []int{0, int(next.PlannedDuration)})
 		}
 
-		errorCh <- i.Start(ctx, config, start, periodic, end)
+		errorCh <- i.StartWithCountdown(ctx, config, start, periodic, end, countdown)
 	}
 
 	// Trigger when pause button is clicked
@@ -54,9 +81,75 @@ func newButtons(ctx context.Context, config *pomodoro.IntervalConfig,
 			errorCh <- err
 		}
 		w.updateWidgets(redrawCh, "Paused, press (s)tart to continue...", "", "", []int{})
+		i, err = pomodoro.Last(config)
+		errorCh <- err
+		notifyEvent(notify.EventPause, i)
+	}
+
+	// Show the interval coming next, not started
+	showNext := func(message string, i pomodoro.Interval) {
+		w.updateWidgets(redrawCh, message, i.Category, fmt.Sprint(i.PlannedDuration), []int{0, int(i.PlannedDuration)})
+	}
+
+	// Trigger when cancel button is clicked
+	cancelInterval := func() {
+		i, err := pomodoro.Last(config)
+		if err == pomodoro.ErrNoIntervals {
+			return
+		}
+		errorCh <- err
+		if err := i.Cancel(config); err != nil {
+			if errors.Is(err, pomodoro.ErrIntervalCompleted) {
+				return
+			}
+			errorCh <- err
+		}
+		w.updateWidgets(redrawCh, "Cancelled, press (s)tart for the next interval", "", "", []int{})
+	}
+
+	// Trigger when skip button is clicked
+	skipInterval := func() {
+		i, err := pomodoro.GetInterval(config)
+		errorCh <- err
+		next, err := i.Skip(config)
+		errorCh <- err
+		showNext("Skipped, press (s)tart to continue...", next)
+	}
+
+	// Trigger when reset button is clicked
+	resetCycle := func() {
+		next, err := pomodoro.ResetCycle(config)
+		errorCh <- err
+		showNext("Cycle reset, press (s)tart to continue...", next)
+	}
+
+	// Trigger when a label or a note is entered
+	annotate := func(text string) {
+		i, err := pomodoro.Annotate(config, text)
+		switch {
+		case errors.Is(err, pomodoro.ErrNoIntervals):
+			w.updateWidgets(redrawCh, "No pomodoro to note yet", "", "", []int{})
+		case err != nil:
+			errorCh <- err
+		case i.State == pomodoro.StateDone || i.State == pomodoro.StateCancelled:
+			w.updateWidgets(redrawCh, "Noted on the last pomodoro", "", "", []int{})
+		default:
+			w.updateWidgets(redrawCh, fmt.Sprintf("Pomodoro labelled %q", i.Label), "", "", []int{})
+		}
+	}
+
+	in, err := newInput(func(text string) error {
+		go annotate(text)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("newButtons: %w", err)
 	}
 
 	startB, err := button.New("(s)tart", func() error {
+		if in.active() {
+			return nil
+		}
 		go startInterval()
 		return nil
 	},
@@ -68,6 +161,9 @@ func newButtons(ctx context.Context, config *pomodoro.IntervalConfig,
 	}
 
 	pauseB, err := button.New("(p)ause", func() error {
+		if in.active() {
+			return nil
+		}
 		go pauseInterval()
 		return nil
 	},
@@ -79,5 +175,60 @@ func newButtons(ctx context.Context, config *pomodoro.IntervalConfig,
 		return nil, fmt.Errorf("newButtons: %w", err)
 	}
 
-	return &buttonSet{btStart: startB, btPause: pauseB}, nil
+	cancelB, err := button.New("(c)ancel", func() error {
+		if in.active() {
+			return nil
+		}
+		go cancelInterval()
+		return nil
+	},
+		button.GlobalKey('c'),
+		button.WidthFor("(c)ancel"),
+		button.FillColor(cell.ColorNumber(196)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("newButtons: %w", err)
+	}
+
+	skipB, err := button.New("s(k)ip", func() error {
+		if in.active() {
+			return nil
+		}
+		go skipInterval()
+		return nil
+	},
+		button.GlobalKey('k'),
+		button.WidthFor("s(k)ip"),
+		button.FillColor(cell.ColorNumber(39)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("newButtons: %w", err)
+	}
+
+	resetB, err := button.New("(r)eset cycle", func() error {
+		if in.active() {
+			return nil
+		}
+		go resetCycle()
+		return nil
+	},
+		button.GlobalKey('r'),
+		button.WidthFor("(r)eset cycle"),
+		button.FillColor(cell.ColorNumber(141)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("newButtons: %w", err)
+	}
+
+	// Show the interval left unfinished by the last session
+	i, err := pomodoro.Resume(config)
+	switch {
+	case err == nil:
+		go w.updateWidgets(redrawCh, "Paused, press (s)tart to continue...", i.Category,
+			fmt.Sprint(i.PlannedDuration-i.ActualDuration), []int{int(i.ActualDuration), int(i.PlannedDuration)})
+	case err != pomodoro.ErrNoIntervals:
+		return nil, fmt.Errorf("newButtons: %w", err)
+	}
+
+	return &buttonSet{btStart: startB, btPause: pauseB, btCancel: cancelB, btSkip: skipB, btReset: resetB, in: in}, nil
 }
@@ -8,29 +8,101 @@ import (
 	"github.com/mum4k/termdash/container/grid"
 	"github.com/mum4k/termdash/linestyle"
 	"github.com/mum4k/termdash/terminal/terminalapi"
+	"haonguyen.tech/interactiveTools/pomo/notify"
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
 
+// rootID identifies the container holding the current screen, timerID the
+// one of the timer, titled with the keys to press
+const (
+	rootID  = "root"
+	timerID = "timer"
+)
+
+const (
+	quitTitle   = "Press Q to quit"
+	typingTitle = "Typing, press Enter to save or Esc to drop"
+)
+
+// layout switches the terminal between the timer and the summary screens
+type layout struct {
+	c           *container.Container
+	s           *summary
+	timer       []container.Option
+	summary     []container.Option
+	showSummary bool
+	// in takes the keys while typing a label or a note
+	in *input
+}
+
+// toggle shows the summary screen in place of the timer one or the reverse
+func (l *layout) toggle() error {
+	l.showSummary = !l.showSummary
+	if !l.showSummary {
+		return l.c.Update(rootID, l.timer...)
+	}
+
+	if err := l.s.update(); err != nil {
+		return err
+	}
+	return l.c.Update(rootID, l.summary...)
+}
+
+// typing titles the timer with the keys of the input while typing, since the
+// other keys are ignored meanwhile
+func (l *layout) typing(typing bool) error {
+	title := quitTitle
+	if typing {
+		title = typingTitle
+	}
+	return l.c.Update(timerID, container.BorderTitle(title))
+}
+
 // newGrid Get the container and define the layout for widgets
-func newGrid(ctx context.Context, t terminalapi.Terminal, config *pomodoro.IntervalConfig, errorCh chan error, redrawCh chan<- bool) (*container.Container, error) {
+func newGrid(ctx context.Context, t terminalapi.Terminal, config *pomodoro.IntervalConfig, n *notify.Notifier, errorCh chan error, redrawCh chan<- bool) (*layout, error) {
 	widgets, err := newWidget(ctx, errorCh)
 	if err != nil {
 		return nil, err
 	}
-	b, err := newButtons(ctx, config, widgets, errorCh, redrawCh)
+	s, err := newSummary(config)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.update(); err != nil {
+		return nil, err
+	}
+	b, err := newButtons(ctx, config, n, widgets, s, errorCh, redrawCh)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &layout{s: s, in: b.in}
+	if l.timer, err = timerGrid(widgets, s, b); err != nil {
+		return nil, err
+	}
+	if l.summary, err = summaryGrid(s); err != nil {
+		return nil, err
+	}
+
+	if l.c, err = container.New(t, append(l.timer, container.ID(rootID))...); err != nil {
+		return nil, err
+	}
+	l.in.onTyping = l.typing
+	return l, nil
+}
 
+// timerGrid lays out the current interval with a summary panel and the buttons
+func timerGrid(widgets *widgets, s *summary, b *buttonSet) ([]container.Option, error) {
 	builder := grid.New()
 
 	// First row
 	builder.Add(
-		grid.RowHeightPerc(70,
+		grid.RowHeightPerc(55,
 			grid.ColWidthPercWithOpts(60,
 				[]container.Option{
 					container.AlignHorizontal(align.HorizontalCenter),
-					container.BorderTitle("Press Q to quit"),
+					container.ID(timerID),
+					container.BorderTitle(quitTitle),
 					container.Border(linestyle.Light),
 				},
 				grid.RowHeightPerc(70,
@@ -63,18 +135,44 @@ func newGrid(ctx context.Context, t terminalapi.Terminal, config *pomodoro.Inter
 
 	// Second row
 	builder.Add(
-		grid.RowHeightPerc(30,
-			grid.ColWidthPerc(50, grid.Widget(b.btStart)),
-			grid.ColWidthPerc(50, grid.Widget(b.btPause))),
+		grid.RowHeightPerc(24,
+			grid.ColWidthPerc(50, grid.Widget(s.bcDay,
+				container.Border(linestyle.Light),
+				container.BorderTitle("Focused minutes, press V for the summary"))),
+			grid.ColWidthPerc(50, grid.Widget(s.lcHour,
+				container.Border(linestyle.Light),
+				container.BorderTitle("Pomodoros per hour")))),
 	)
 
-	gridOpts, err := builder.Build()
-	if err != nil {
-		return nil, err
-	}
-	c, err := container.New(t, gridOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return c, nil
+	// Third row
+	builder.Add(
+		grid.RowHeightPerc(8, grid.Widget(b.in)),
+	)
+
+	// Fourth row
+	builder.Add(
+		grid.RowHeightPerc(13,
+			grid.ColWidthPerc(20, grid.Widget(b.btStart)),
+			grid.ColWidthPerc(20, grid.Widget(b.btPause)),
+			grid.ColWidthPerc(20, grid.Widget(b.btCancel)),
+			grid.ColWidthPerc(20, grid.Widget(b.btSkip)),
+			grid.ColWidthPerc(20, grid.Widget(b.btReset))),
+	)
+
+	return builder.Build()
+}
+
+// summaryGrid lays out the summary screen
+func summaryGrid(s *summary) ([]container.Option, error) {
+	builder := grid.New()
+	builder.Add(
+		grid.RowHeightPerc(50, grid.Widget(s.bcDay,
+			container.Border(linestyle.Light),
+			container.BorderTitle("Focused minutes per day, press V to go back, Q to quit"))),
+		grid.RowHeightPerc(50, grid.Widget(s.lcHour,
+			container.Border(linestyle.Light),
+			container.BorderTitle("Pomodoros done per hour of day over the last week"))),
+	)
+
+	return builder.Build()
 }
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"sync/atomic"
+
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+	"github.com/mum4k/termdash/widgetapi"
+	"github.com/mum4k/termdash/widgets/textinput"
+)
+
+// input is a text input taking the keyboard once (l) is pressed, until Enter
+// submits the text or Esc drops it. Termdash gives the keys to the focused
+// widget and the global shortcuts alike, so the buttons and the other keys
+// are ignored while typing
+type input struct {
+	*textinput.TextInput
+	typing atomic.Bool
+	// onTyping shows the keys of the input when the typing starts or stops
+	onTyping func(typing bool) error
+}
+
+func newInput(onSubmit textinput.SubmitFn) (*input, error) {
+	in := &input{}
+	ti, err := textinput.New(
+		textinput.Label("Pomodoro label, or note during a break: "),
+		textinput.PlaceHolder("press (l)"),
+		textinput.ClearOnSubmit(),
+		textinput.OnSubmit(func(text string) error {
+			if err := in.setTyping(false); err != nil {
+				return err
+			}
+			if text == "" {
+				return nil
+			}
+			return onSubmit(text)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	in.TextInput = ti
+	return in, nil
+}
+
+// active tells if the keyboard goes to the input
+func (in *input) active() bool {
+	return in.typing.Load()
+}
+
+func (in *input) setTyping(typing bool) error {
+	in.typing.Store(typing)
+	if in.onTyping == nil {
+		return nil
+	}
+	return in.onTyping(typing)
+}
+
+// Keyboard implements widgetapi.Widget.Keyboard
+func (in *input) Keyboard(k *terminalapi.Keyboard) error {
+	if !in.typing.Load() {
+		if k.Key == 'l' || k.Key == 'L' {
+			return in.setTyping(true)
+		}
+		return nil
+	}
+
+	if k.Key == keyboard.KeyEsc {
+		in.ReadAndClear()
+		return in.setTyping(false)
+	}
+	return in.TextInput.Keyboard(k)
+}
+
+// Options implements widgetapi.Widget.Options, taking the keys not focused
+func (in *input) Options() widgetapi.Options {
+	opts := in.TextInput.Options()
+	opts.WantKeyboard = widgetapi.KeyScopeGlobal
+	return opts
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"time"
+
+	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/widgets/barchart"
+	"github.com/mum4k/termdash/widgets/linechart"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// summaryDays is the number of days shown in the summary
+const summaryDays = 7
+
+type summary struct {
+	bcDay  *barchart.BarChart
+	lcHour *linechart.LineChart
+	config *pomodoro.IntervalConfig
+}
+
+func newSummary(config *pomodoro.IntervalConfig) (*summary, error) {
+	bc, err := barchart.New(
+		barchart.ShowValues(),
+		barchart.BarColors([]cell.Color{cell.ColorBlue}),
+		barchart.ValueColors([]cell.Color{cell.ColorBlack}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lc, err := linechart.New(
+		linechart.AxesCellOpts(cell.FgColor(cell.ColorRed)),
+		linechart.YLabelCellOpts(cell.FgColor(cell.ColorBlue)),
+		linechart.XLabelCellOpts(cell.FgColor(cell.ColorCyan)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary{bcDay: bc, lcHour: lc, config: config}, nil
+}
+
+// update reloads the charts from the repository
+func (s *summary) update() error {
+	now := time.Now()
+	daily, err := pomodoro.DailySummary(s.config, now, summaryDays)
+	if err != nil {
+		return err
+	}
+
+	minutes := make([]int, len(daily))
+	labels := make([]string, len(daily))
+	max := 0
+	for k, d := range daily {
+		minutes[k] = int(d.Minutes())
+		labels[k] = now.AddDate(0, 0, k-len(daily)+1).Format("Mon")
+		if minutes[k] > max {
+			max = minutes[k]
+		}
+	}
+	// The bar chart needs a maximum above 0
+	if max == 0 {
+		max = 1
+	}
+	if err := s.bcDay.Values(minutes, max, barchart.Labels(labels)); err != nil {
+		return err
+	}
+
+	hourly, err := pomodoro.HourlySummary(s.config, now, summaryDays)
+	if err != nil {
+		return err
+	}
+	count := make([]float64, len(hourly))
+	hours := map[int]string{}
+	for h, c := range hourly {
+		count[h] = float64(c)
+		if h%3 == 0 {
+			hours[h] = time.Date(0, 1, 1, h, 0, 0, 0, time.UTC).Format("15h")
+		}
+	}
+	return s.lcHour.Series("pomodoros", count,
+		linechart.SeriesCellOpts(cell.FgColor(cell.ColorBlue)),
+		linechart.SeriesXLabels(hours),
+	)
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"haonguyen.tech/interactiveTools/pomo/notify"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro/repository"
+)
+
+// stepClock is a pomodoro.Clock whose timers only fire once the test moves
+// it forward, signaling waiting each time the engine waits on it
+type stepClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []stepTimer
+	waiting chan struct{}
+}
+
+type stepTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newStepClock() *stepClock {
+	return &stepClock{now: time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local), waiting: make(chan struct{}, 16)}
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *stepClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, stepTimer{at: c.now.Add(d), ch: ch})
+	c.mu.Unlock()
+
+	c.waiting <- struct{}{}
+	return ch
+}
+
+// Advance moves the clock forward by d, firing the timers due
+func (c *stepClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = timers
+}
+
+func TestHeadlessActions(t *testing.T) {
+	config := pomodoro.NewConfig(repository.NewInMemoryRepo(), 2*time.Second, time.Second, time.Second)
+	clock := newStepClock()
+	config.Clock = clock
+
+	var out bytes.Buffer
+	if err := statusAction(&out, config, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No intervals\n" {
+		t.Errorf("Expect %q, got %q", "No intervals\n", out.String())
+	}
+	if err := pauseAction(&out, config, &notify.Notifier{}); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+		t.Errorf("Expect error %q, got %q", pomodoro.ErrIntervalNotRunning, err)
+	}
+	if err := stopAction(&out, config); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+		t.Errorf("Expect error %q, got %q", pomodoro.ErrIntervalNotRunning, err)
+	}
+
+	// Start in one terminal and pause from another
+	var startOut bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- startAction(context.Background(), &startOut, config, &notify.Notifier{}, "write report")
+	}()
+	// The interval is running once it waits for the first second to pass
+	<-clock.waiting
+
+	if err := startAction(context.Background(), &out, config, &notify.Notifier{}, ""); !errors.Is(err, pomodoro.ErrIntervalRunning) {
+		t.Errorf("Expect error %q, got %q", pomodoro.ErrIntervalRunning, err)
+	}
+
+	out.Reset()
+	if err := statusAction(&out, config, true); err != nil {
+		t.Fatal(err)
+	}
+	expJSON := `{"id":1,"category":"Pomodoro","state":"running",`
+	if !strings.HasPrefix(out.String(), expJSON) || !strings.Contains(out.String(), `"plannedSeconds":2,`) ||
+		!strings.Contains(out.String(), `"label":"write report"`) {
+		t.Errorf("Expect JSON status starting with %q, got %q", expJSON, out.String())
+	}
+
+	out.Reset()
+	if err := pauseAction(&out, config, &notify.Notifier{}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Pomodoro paused, ") {
+		t.Errorf("Expect pause message, got %q", out.String())
+	}
+	if !strings.HasPrefix(startOut.String(), "Pomodoro \"write report\" started, 2s left\nPomodoro paused, ") {
+		t.Errorf("Expect start to report the pause, got %q", startOut.String())
+	}
+
+	out.Reset()
+	if err := stopAction(&out, config); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Pomodoro stopped\n" {
+		t.Errorf("Expect %q, got %q", "Pomodoro stopped\n", out.String())
+	}
+
+	out.Reset()
+	if err := statusAction(&out, config, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Pomodoro \"write report\" cancelled\n" {
+		t.Errorf("Expect %q, got %q", "Pomodoro \"write report\" cancelled\n", out.String())
+	}
+
+	out.Reset()
+	if err := historyAction(&out, config, clock.Now(), 1); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID  START") || !strings.Contains(lines[1], "Pomodoro  cancelled") {
+		t.Errorf("Expect history of the stopped interval, got %q", out.String())
+	}
+}
+
+func TestCycleConfig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sequence  string
+		pomodoros int
+		longBreak bool
+		exp       string
+		expErr    error
+	}{
+		{name: "Default", pomodoros: 4, longBreak: true, exp: "Pomodoro,ShortBreak,Pomodoro,ShortBreak,Pomodoro,ShortBreak,Pomodoro,LongBreak"},
+		{name: "NoLongBreak", pomodoros: 1, exp: "Pomodoro,ShortBreak"},
+		{name: "Sequence", sequence: "P,S,P,L", pomodoros: 4, exp: "Pomodoro,ShortBreak,Pomodoro,LongBreak"},
+		{name: "InvalidSequence", sequence: "S,P", expErr: pomodoro.ErrInvalidCycle},
+		{name: "InvalidPomodoros", pomodoros: 0, expErr: pomodoro.ErrInvalidCycle},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("cycle.sequence", tc.sequence)
+			viper.Set("cycle.pomodoros", tc.pomodoros)
+			viper.Set("cycle.long-break", tc.longBreak)
+			defer viper.Reset()
+
+			c, err := cycleConfig()
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expect error %q, got %q", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(c, ",") != tc.exp {
+				t.Errorf("Expect %q, got %q", tc.exp, strings.Join(c, ","))
+			}
+		})
+	}
+}
+
+func TestExportAction(t *testing.T) {
+	repo := repository.NewInMemoryRepo()
+	for _, day := range []int{1, 3} {
+		i := pomodoro.Interval{
+			StartTime:       time.Date(2024, 3, day, 9, 30, 0, 0, time.UTC),
+			PlannedDuration: time.Minute,
+			ActualDuration:  time.Minute,
+			Category:        pomodoro.CategoryPomodoro,
+			State:           pomodoro.StateDone,
+		}
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := pomodoro.NewConfig(repo, time.Minute, time.Minute, time.Minute)
+	// Left out, not started
+	if _, err := pomodoro.GetInterval(config); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)
+	header := "id,start_time,category,state,planned_seconds,actual_seconds,label,note\n"
+	first := "1,2024-03-01T09:30:00Z,Pomodoro,done,60,60,,\n"
+	second := "2,2024-03-03T09:30:00Z,Pomodoro,done,60,60,,\n"
+
+	testCases := []struct {
+		name     string
+		from, to string
+		expected string
+		expErr   bool
+	}{
+		{name: "All", expected: header + first + second},
+		{name: "From", from: "2024-03-02", expected: header + second},
+		{name: "To", to: "2024-03-01", expected: header + first},
+		{name: "Empty", from: "2024-03-02", to: "2024-03-02", expected: header},
+		{name: "InvalidDay", from: "March", expErr: true},
+		{name: "ToBeforeFrom", from: "2024-03-02", to: "2024-03-01", expErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := exportAction(&out, config, now, "csv", tc.from, tc.to)
+			if tc.expErr {
+				if err == nil {
+					t.Error("Expect error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("Expect %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestLabelActions(t *testing.T) {
+	repo := repository.NewInMemoryRepo()
+	config := pomodoro.NewConfig(repo, time.Minute, time.Minute, time.Minute)
+	now := time.Now()
+
+	var out bytes.Buffer
+	if err := noteAction(&out, config, "nothing yet"); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expect error %q, got %q", pomodoro.ErrNoIntervals, err)
+	}
+
+	for _, label := range []string{"report", "email", "report"} {
+		i := pomodoro.Interval{
+			StartTime:       now,
+			PlannedDuration: time.Minute,
+			ActualDuration:  time.Minute,
+			Category:        pomodoro.CategoryPomodoro,
+			State:           pomodoro.StateDone,
+			Label:           label,
+		}
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A short break comes next
+	if err := startAction(context.Background(), &out, config, &notify.Notifier{}, "rest"); !errors.Is(err, pomodoro.ErrNotPomodoro) {
+		t.Errorf("Expect error %q, got %q", pomodoro.ErrNotPomodoro, err)
+	}
+
+	out.Reset()
+	if err := noteAction(&out, config, "draft sent"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), `Noted on Pomodoro "report" of `) {
+		t.Errorf("Expect note message, got %q", out.String())
+	}
+	i, err := repo.ByID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Note != "draft sent" {
+		t.Errorf("Expect note %q, got %q", "draft sent", i.Note)
+	}
+
+	out.Reset()
+	if err := labelsAction(&out, config, now, 1); err != nil {
+		t.Fatal(err)
+	}
+	expected := "LABEL   POMODOROS  FOCUS\nreport  2          2m0s\nemail   1          1m0s\n"
+	if out.String() != expected {
+		t.Errorf("Expect %q, got %q", expected, out.String())
+	}
+}
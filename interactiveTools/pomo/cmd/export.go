@@ -0,0 +1,120 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/export"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the intervals as CSV, JSON or iCalendar",
+	Long: `Export the intervals started between --from and --to, both days included,
+to the standard output.
+
+The csv format has the durations in seconds, to compute statistics with
+colStats. The ics format holds the intervals done as calendar events.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		return exportAction(os.Stdout, config, time.Now(), format, from, to)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("format", "f", export.FormatCSV, "Format: csv, json or ics")
+	exportCmd.Flags().String("from", "", "First day to export, as 2006-01-02 (default is the first interval)")
+	exportCmd.Flags().String("to", "", "Last day to export, as 2006-01-02 (default is today)")
+}
+
+func exportAction(out io.Writer, config *pomodoro.IntervalConfig, now time.Time, format, from, to string) error {
+	start, err := parseDay(from, time.Time{}, now.Location())
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	end, err := parseDay(to, now, now.Location())
+	if err != nil {
+		return fmt.Errorf("--to: %w", err)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("--to %s is before --from %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	intervals, err := pomodoro.History(config, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	// The intervals not started yet have no start time to export
+	intervals = slices.DeleteFunc(intervals, func(i pomodoro.Interval) bool {
+		return i.State == pomodoro.StateNotStarted
+	})
+
+	// The events end when the intervals last stopped running
+	ends := map[int64]time.Time{}
+	for _, i := range intervals {
+		if format != export.FormatICS {
+			break
+		}
+		if ends[i.ID], err = i.End(config); err != nil {
+			return err
+		}
+	}
+	return export.Write(out, format, intervals, ends, now)
+}
+
+// parseDay returns the start of the day s, or of the day def when s is empty
+func parseDay(s string, def time.Time, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		if def.IsZero() {
+			return def, nil
+		}
+		y, m, d := def.In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), nil
+	}
+	return time.ParseInLocation("2006-01-02", s, loc)
+}
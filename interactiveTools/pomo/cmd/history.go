@@ -0,0 +1,113 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the intervals of the last days",
+	Long: `List the intervals of the last days, or with --by-label, the pomodoros
+done and the time focused on each label.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		if days < 1 {
+			return fmt.Errorf("invalid number of days: %d", days)
+		}
+		byLabel, err := cmd.Flags().GetBool("by-label")
+		if err != nil {
+			return err
+		}
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		if byLabel {
+			return labelsAction(os.Stdout, config, time.Now(), days)
+		}
+		return historyAction(os.Stdout, config, time.Now(), days)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().Int("days", 1, "Number of days to list, today included")
+	historyCmd.Flags().Bool("by-label", false, "Total the pomodoros by label")
+}
+
+func historyAction(out io.Writer, config *pomodoro.IntervalConfig, now time.Time, days int) error {
+	start, end := pomodoro.LastDays(now, days)
+	intervals, err := pomodoro.History(config, start, end)
+	if err != nil {
+		return err
+	}
+	if len(intervals) == 0 {
+		_, err := fmt.Fprintln(out, "No intervals")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTART\tCATEGORY\tSTATE\tDURATION\tLABEL")
+	for _, i := range intervals {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s/%s\t%s\n", i.ID, i.StartTime.In(now.Location()).Format("2006-01-02 15:04"),
+			i.Category, pomodoro.StateName(i.State), i.ActualDuration, i.PlannedDuration, i.Label)
+	}
+	return w.Flush()
+}
+
+func labelsAction(out io.Writer, config *pomodoro.IntervalConfig, now time.Time, days int) error {
+	start, end := pomodoro.LastDays(now, days)
+	totals, err := pomodoro.LabelSummary(config, start, end)
+	if err != nil {
+		return err
+	}
+	if len(totals) == 0 {
+		_, err := fmt.Fprintln(out, "No pomodoros")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tPOMODOROS\tFOCUS")
+	for _, t := range totals {
+		label := t.Label
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", label, t.Pomodoros, t.Focus)
+	}
+	return w.Flush()
+}
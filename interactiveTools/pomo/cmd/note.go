@@ -0,0 +1,63 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:          "note TEXT...",
+	Short:        "Note what came out of the last pomodoro",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		return noteAction(os.Stdout, config, strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+}
+
+func noteAction(out io.Writer, config *pomodoro.IntervalConfig, note string) error {
+	i, err := pomodoro.LastPomodoro(config)
+	if err != nil {
+		return err
+	}
+	if err := i.SetNote(config, note); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Noted on %s of %s\n", intervalName(i), i.StartTime.Format("2006-01-02 15:04"))
+	return err
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/notify"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:          "pause",
+	Short:        "Pause the running interval",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		n, err := newNotifier(os.Stdout)
+		if err != nil {
+			return err
+		}
+		return pauseAction(os.Stdout, config, n)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+}
+
+func pauseAction(out io.Writer, config *pomodoro.IntervalConfig, n *notify.Notifier) error {
+	i, err := pomodoro.Last(config)
+	if errors.Is(err, pomodoro.ErrNoIntervals) {
+		return pomodoro.ErrIntervalNotRunning
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := i.Pause(config); err != nil {
+		return err
+	}
+	// Paused with the time elapsed until now
+	if i, err = pomodoro.Last(config); err != nil {
+		return err
+	}
+	notifyEvent(context.Background(), n, notify.EventPause, i)
+	_, err = fmt.Fprintf(out, "%s paused, %s left\n", i.Category, i.PlannedDuration-i.ActualDuration)
+	return err
+}
@@ -1,3 +1,6 @@
+//go:build inmemory
+// +build inmemory
+
 /*
 Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
 
@@ -26,6 +29,7 @@ import (
 	"haonguyen.tech/interactiveTools/pomo/pomodoro/repository"
 )
 
+// getRepo returns a repository lost on exit, the build having no SQLite support
 func getRepo() (pomodoro.Repository, error) {
 	return repository.NewInMemoryRepo(), nil
 }
@@ -0,0 +1,71 @@
+//go:build !inmemory
+// +build !inmemory
+
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro/repository"
+)
+
+// inMemoryDB is the --db value selecting a repository lost on exit
+const inMemoryDB = ":memory:"
+
+// getRepo returns the repository stored in the SQLite file set by --db
+func getRepo() (pomodoro.Repository, error) {
+	dbFile := viper.GetString("db")
+	if dbFile == inMemoryDB {
+		return repository.NewInMemoryRepo(), nil
+	}
+
+	if dbFile == "" {
+		var err error
+		if dbFile, err = defaultDBFile(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dbFile), 0o755); err != nil {
+		return nil, err
+	}
+
+	return repository.NewSqlite3Repo(dbFile)
+}
+
+// defaultDBFile returns the database file in the user's data directory,
+// $XDG_DATA_HOME or ~/.local/share
+func defaultDBFile() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pomo", "pomo.db"), nil
+}
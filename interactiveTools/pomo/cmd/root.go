@@ -30,6 +30,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"haonguyen.tech/interactiveTools/pomo/app"
+	"haonguyen.tech/interactiveTools/pomo/notify"
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
 
@@ -40,23 +41,65 @@ var rootCmd = &cobra.Command{
 	Use:   "pomo",
 	Short: "A brief description of your application",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		repo, err := getRepo()
+		config, err := newConfig()
 		if err != nil {
 			return err
 		}
-		config := pomodoro.NewConfig(
-			repo,
-			viper.GetDuration("pomo"),
-			viper.GetDuration("short"),
-			viper.GetDuration("long"),
-		)
-
-		return rootAction(os.Stdout, config)
+		n, err := newNotifier(os.Stdout)
+		if err != nil {
+			return err
+		}
+		return rootAction(os.Stdout, config, n)
 	},
 }
 
-func rootAction(w io.Writer, config *pomodoro.IntervalConfig) error {
-	a, err := app.New(config)
+// newConfig returns the interval configuration using the repository set by --db
+func newConfig() (*pomodoro.IntervalConfig, error) {
+	repo, err := getRepo()
+	if err != nil {
+		return nil, err
+	}
+	cycle, err := cycleConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config := pomodoro.NewConfig(
+		repo,
+		viper.GetDuration("pomo"),
+		viper.GetDuration("short"),
+		viper.GetDuration("long"),
+	)
+	config.Cycle = cycle
+	config.AutoStartBreaks = viper.GetBool("auto-start-breaks")
+	config.AutoStartPomodoros = viper.GetBool("auto-start-pomodoros")
+	config.AutoStartDelay = viper.GetDuration("auto-start-delay")
+	return config, nil
+}
+
+// cycleConfig returns the cycle set in the configuration file, a sequence
+// like "P,S,P,S,P,L" taking precedence over the number of pomodoros per set
+func cycleConfig() ([]string, error) {
+	if s := viper.GetString("cycle.sequence"); s != "" {
+		return pomodoro.ParseCycle(s)
+	}
+	return pomodoro.NewCycle(viper.GetInt("cycle.pomodoros"), viper.GetBool("cycle.long-break"))
+}
+
+// newNotifier returns the notifier set in the configuration file, ringing the bell on bell
+func newNotifier(bell io.Writer) (*notify.Notifier, error) {
+	n := &notify.Notifier{Desktop: viper.GetBool("notify.desktop")}
+	if viper.GetBool("notify.bell") {
+		n.Bell = bell
+	}
+	if err := viper.UnmarshalKey("notify.hooks", &n.Hooks); err != nil {
+		return nil, fmt.Errorf("notify.hooks: %w", err)
+	}
+	return n, nil
+}
+
+func rootAction(w io.Writer, config *pomodoro.IntervalConfig, n *notify.Notifier) error {
+	a, err := app.New(config, n)
 	if err != nil {
 		return err
 	}
@@ -77,23 +120,41 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pomo.yaml)")
+	rootCmd.PersistentFlags().String("db", "", `SQLite database file (default is $XDG_DATA_HOME/pomo/pomo.db), ":memory:" to keep nothing`)
+
+	rootCmd.PersistentFlags().DurationP("pomo", "p", 25*time.Minute, "Pomodoro duration")
+	rootCmd.PersistentFlags().DurationP("short", "s", 5*time.Minute, "Short break duration")
+	rootCmd.PersistentFlags().DurationP("long", "l", 15*time.Minute, "Long break duration")
+	rootCmd.PersistentFlags().Bool("auto-start-breaks", false, "Start the break automatically when a pomodoro is done")
+	rootCmd.PersistentFlags().Bool("auto-start-pomodoros", false, "Start the pomodoro automatically when a break is done")
+	rootCmd.PersistentFlags().Duration("auto-start-delay", 5*time.Second, "Countdown before an interval starts automatically")
 
-	rootCmd.Flags().DurationP("pomo", "p", 25*time.Minute, "Pomodoro duration")
-	rootCmd.Flags().DurationP("short", "s", 5*time.Minute, "Short break duration")
-	rootCmd.Flags().DurationP("long", "l", 15*time.Minute, "Long break duration")
+	viper.SetDefault("cycle.pomodoros", 4)
+	viper.SetDefault("cycle.long-break", true)
+	viper.SetDefault("notify.bell", true)
+	viper.SetDefault("notify.desktop", true)
 
-	err := viper.BindPFlag("pomo", rootCmd.Flags().Lookup("pomo"))
+	err := viper.BindPFlag("db", rootCmd.PersistentFlags().Lookup("db"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "BindPFlag %s", err)
 	}
-	err = viper.BindPFlag("short", rootCmd.Flags().Lookup("short"))
+	err = viper.BindPFlag("pomo", rootCmd.PersistentFlags().Lookup("pomo"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "BindPFlag %s", err)
 	}
-	err = viper.BindPFlag("long", rootCmd.Flags().Lookup("long"))
+	err = viper.BindPFlag("short", rootCmd.PersistentFlags().Lookup("short"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "BindPFlag %s", err)
 	}
+	err = viper.BindPFlag("long", rootCmd.PersistentFlags().Lookup("long"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "BindPFlag %s", err)
+	}
+	for _, name := range []string{"auto-start-breaks", "auto-start-pomodoros", "auto-start-delay"} {
+		if err := viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name)); err != nil {
+			fmt.Fprintf(os.Stderr, "BindPFlag %s", err)
+		}
+	}
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,150 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/notify"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// startCmd represents the start command
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start or resume the interval without the terminal UI",
+	Long: `Start the next interval, or resume the paused one, and wait for it to end.
+
+The interval can be paused or stopped from another terminal with "pomo pause"
+and "pomo stop". Interrupting the command pauses the interval.
+
+With --auto-start-breaks or --auto-start-pomodoros, the next interval starts
+after the --auto-start-delay countdown and the command keeps running.
+
+A pomodoro gets the label given with --label, what it is spent on.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		label, err := cmd.Flags().GetString("label")
+		if err != nil {
+			return err
+		}
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		n, err := newNotifier(os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+		go func() {
+			select {
+			case <-sig:
+				// Pause rather than cancel, like quitting the terminal UI
+				if err := pomodoro.Suspend(config); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		return startAction(ctx, os.Stdout, config, n, label)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().String("label", "", "What the pomodoro is spent on")
+}
+
+func startAction(ctx context.Context, out io.Writer, config *pomodoro.IntervalConfig, n *notify.Notifier, label string) error {
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		return err
+	}
+	if i.State == pomodoro.StateRunning {
+		return fmt.Errorf("%w: %s", pomodoro.ErrIntervalRunning, i.Category)
+	}
+	if label != "" {
+		if err := i.SetLabel(config, label); err != nil {
+			return fmt.Errorf("--label: %w", err)
+		}
+		if i, err = pomodoro.Last(config); err != nil {
+			return err
+		}
+	}
+
+	start := func(i pomodoro.Interval) {
+		fmt.Fprintf(out, "%s started, %s left\n", intervalName(i), i.PlannedDuration-i.ActualDuration)
+		notifyEvent(ctx, n, notify.EventStart, i)
+	}
+	periodic := func(pomodoro.Interval) {}
+	end := func(i pomodoro.Interval) {
+		fmt.Fprintf(out, "%s done\n", i.Category)
+		notifyEvent(ctx, n, notify.EventEnd, i)
+	}
+	// Announce the interval starting automatically once, not every second
+	var announced int64
+	countdown := func(next pomodoro.Interval, left time.Duration) {
+		if next.ID == announced {
+			return
+		}
+		announced = next.ID
+		fmt.Fprintf(out, "%s starts in %s\n", next.Category, left)
+	}
+	if err := i.StartWithCountdown(ctx, config, start, periodic, end, countdown); err != nil {
+		return err
+	}
+
+	// Report when paused or stopped rather than done
+	if i, err = pomodoro.Last(config); err != nil {
+		return err
+	}
+	switch i.State {
+	case pomodoro.StatePaused:
+		_, err = fmt.Fprintf(out, "%s paused, %s left\n", i.Category, i.PlannedDuration-i.ActualDuration)
+	case pomodoro.StateCancelled:
+		_, err = fmt.Fprintf(out, "%s stopped\n", i.Category)
+	}
+	return err
+}
+
+// notifyEvent notifies the event, reporting a failure without stopping the interval
+func notifyEvent(ctx context.Context, n *notify.Notifier, event string, i pomodoro.Interval) {
+	if err := n.Notify(ctx, event, i); err != nil {
+		fmt.Fprintf(os.Stderr, "notify: %s\n", err)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current interval",
+	Long: `Show the current interval in one line, or as JSON with --json, for
+instance to display it in a shell prompt or a tmux status line.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		return statusAction(os.Stdout, config, asJSON)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().Bool("json", false, "Print the status as JSON")
+}
+
+// intervalStatus is the JSON status of an interval, State being "none" without any
+type intervalStatus struct {
+	ID               int64     `json:"id,omitempty"`
+	Category         string    `json:"category,omitempty"`
+	State            string    `json:"state"`
+	StartTime        time.Time `json:"startTime"`
+	PlannedSeconds   int       `json:"plannedSeconds"`
+	ActualSeconds    int       `json:"actualSeconds"`
+	RemainingSeconds int       `json:"remainingSeconds"`
+	Label            string    `json:"label,omitempty"`
+}
+
+// intervalName names the interval by its category and label, like Pomodoro "write report"
+func intervalName(i pomodoro.Interval) string {
+	if i.Label == "" {
+		return i.Category
+	}
+	return fmt.Sprintf("%s %q", i.Category, i.Label)
+}
+
+func statusAction(out io.Writer, config *pomodoro.IntervalConfig, asJSON bool) error {
+	i, err := pomodoro.Last(config)
+	if err != nil && !errors.Is(err, pomodoro.ErrNoIntervals) {
+		return err
+	}
+	noInterval := err != nil
+
+	if asJSON {
+		s := intervalStatus{State: "none"}
+		if !noInterval {
+			s = intervalStatus{
+				ID:               i.ID,
+				Category:         i.Category,
+				State:            pomodoro.StateName(i.State),
+				StartTime:        i.StartTime,
+				PlannedSeconds:   int(i.PlannedDuration.Seconds()),
+				ActualSeconds:    int(i.ActualDuration.Seconds()),
+				RemainingSeconds: int((i.PlannedDuration - i.ActualDuration).Seconds()),
+				Label:            i.Label,
+			}
+		}
+		return json.NewEncoder(out).Encode(s)
+	}
+
+	switch {
+	case noInterval:
+		_, err = fmt.Fprintln(out, "No intervals")
+	case i.State == pomodoro.StateRunning || i.State == pomodoro.StatePaused:
+		_, err = fmt.Fprintf(out, "%s %s, %s left\n", intervalName(i), pomodoro.StateName(i.State), i.PlannedDuration-i.ActualDuration)
+	default:
+		_, err = fmt.Fprintf(out, "%s %s\n", intervalName(i), pomodoro.StateName(i.State))
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2024 Hao Nguyen <hao@haonguyen.tech>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// stopCmd represents the stop command
+var stopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "Cancel the running or paused interval",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := newConfig()
+		if err != nil {
+			return err
+		}
+		return stopAction(os.Stdout, config)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
+
+func stopAction(out io.Writer, config *pomodoro.IntervalConfig) error {
+	i, err := pomodoro.Stop(config)
+	if errors.Is(err, pomodoro.ErrNoIntervals) {
+		return pomodoro.ErrIntervalNotRunning
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%s stopped\n", i.Category)
+	return err
+}
@@ -0,0 +1,165 @@
+// Package export writes the intervals out of pomo, as CSV for spreadsheets
+// and colStats, JSON, or iCalendar events for calendar applications
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatICS  = "ics"
+)
+
+var ErrInvalidFormat = errors.New("invalid export format")
+
+// Record is an exported interval
+type Record struct {
+	ID             int64     `json:"id"`
+	StartTime      time.Time `json:"startTime"`
+	Category       string    `json:"category"`
+	State          string    `json:"state"`
+	PlannedSeconds int       `json:"plannedSeconds"`
+	ActualSeconds  int       `json:"actualSeconds"`
+	Label          string    `json:"label,omitempty"`
+	Note           string    `json:"note,omitempty"`
+}
+
+func newRecord(i pomodoro.Interval) Record {
+	return Record{
+		ID:             i.ID,
+		StartTime:      i.StartTime,
+		Category:       i.Category,
+		State:          pomodoro.StateName(i.State),
+		PlannedSeconds: int(i.PlannedDuration.Seconds()),
+		ActualSeconds:  int(i.ActualDuration.Seconds()),
+		Label:          i.Label,
+		Note:           i.Note,
+	}
+}
+
+// Write writes the intervals to w in the format. The iCalendar events end at
+// ends, see ICS, and are stamped with now
+func Write(w io.Writer, format string, intervals []pomodoro.Interval, ends map[int64]time.Time, now time.Time) error {
+	switch format {
+	case FormatCSV:
+		return CSV(w, intervals)
+	case FormatJSON:
+		return JSON(w, intervals)
+	case FormatICS:
+		return ICS(w, intervals, ends, now)
+	default:
+		return fmt.Errorf("%w: %q, expected %s, %s or %s", ErrInvalidFormat, format, FormatCSV, FormatJSON, FormatICS)
+	}
+}
+
+// CSV writes the intervals with a header line, the durations in seconds. The
+// label and note come last to keep the numeric columns in place
+func CSV(w io.Writer, intervals []pomodoro.Interval) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "start_time", "category", "state", "planned_seconds", "actual_seconds", "label", "note"})
+	for _, i := range intervals {
+		r := newRecord(i)
+		cw.Write([]string{
+			strconv.FormatInt(r.ID, 10),
+			r.StartTime.Format(time.RFC3339),
+			r.Category,
+			r.State,
+			strconv.Itoa(r.PlannedSeconds),
+			strconv.Itoa(r.ActualSeconds),
+			r.Label,
+			r.Note,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// JSON writes the intervals as an array of records
+func JSON(w io.Writer, intervals []pomodoro.Interval) error {
+	records := make([]Record, 0, len(intervals))
+	for _, i := range intervals {
+		records = append(records, newRecord(i))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// ICS writes the intervals done as the events of an iCalendar titled with
+// their label. An event lasts from the start of the interval to its end in
+// ends by ID, pauses included, or else for the time actually spent on it
+func ICS(w io.Writer, intervals []pomodoro.Interval, ends map[int64]time.Time, now time.Time) error {
+	var b strings.Builder
+	line := func(format string, a ...any) {
+		b.WriteString(fold(fmt.Sprintf(format, a...)))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//haonguyen.tech//pomo//EN")
+	for _, i := range intervals {
+		if i.State != pomodoro.StateDone {
+			continue
+		}
+		line("BEGIN:VEVENT")
+		line("UID:pomo-%d-%d@haonguyen.tech", i.ID, i.StartTime.Unix())
+		line("DTSTAMP:%s", icsTime(now))
+		line("DTSTART:%s", icsTime(i.StartTime))
+		end, ok := ends[i.ID]
+		if !ok {
+			end = i.StartTime.Add(i.ActualDuration)
+		}
+		line("DTEND:%s", icsTime(end))
+		summary := i.Category
+		if i.Label != "" {
+			summary += ": " + i.Label
+		}
+		line("SUMMARY:%s", icsText(summary))
+		if i.Note != "" {
+			line("DESCRIPTION:%s", icsText(i.Note))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// fold splits a content line longer than 75 octets, each following line
+// starting with a space, without splitting a UTF-8 character (RFC 5545 3.1)
+func fold(line string) string {
+	var b strings.Builder
+	// The space starting the following lines counts in their 75 octets
+	for limit := 75; len(line) > limit; limit = 74 {
+		k := limit
+		for !utf8.RuneStart(line[k]) {
+			k--
+		}
+		b.WriteString(line[:k])
+		b.WriteString("\r\n ")
+		line = line[k:]
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// icsText escapes the characters with a meaning in iCalendar text values
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace
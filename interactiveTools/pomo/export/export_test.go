@@ -0,0 +1,119 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/export"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	intervals := []pomodoro.Interval{
+		{ID: 1, StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Label: "report", Note: "draft done; sent to Ann, Bob"},
+		{ID: 2, StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute, ActualDuration: 90 * time.Second,
+			Category: pomodoro.CategoryShortBreak, State: pomodoro.StateCancelled},
+	}
+	// The pomodoro was paused for 15 minutes
+	ends := map[int64]time.Time{1: start.Add(40 * time.Minute)}
+
+	testCases := []struct {
+		name     string
+		format   string
+		expected string
+		expErr   error
+	}{
+		{
+			name:   "CSV",
+			format: export.FormatCSV,
+			expected: "id,start_time,category,state,planned_seconds,actual_seconds,label,note\n" +
+				"1,2024-03-01T09:30:00Z,Pomodoro,done,1500,1500,report,\"draft done; sent to Ann, Bob\"\n" +
+				"2,2024-03-01T09:55:00Z,ShortBreak,cancelled,300,90,,\n",
+		},
+		{
+			name:   "JSON",
+			format: export.FormatJSON,
+			expected: `[
+  {
+    "id": 1,
+    "startTime": "2024-03-01T09:30:00Z",
+    "category": "Pomodoro",
+    "state": "done",
+    "plannedSeconds": 1500,
+    "actualSeconds": 1500,
+    "label": "report",
+    "note": "draft done; sent to Ann, Bob"
+  },
+  {
+    "id": 2,
+    "startTime": "2024-03-01T09:55:00Z",
+    "category": "ShortBreak",
+    "state": "cancelled",
+    "plannedSeconds": 300,
+    "actualSeconds": 90
+  }
+]
+`,
+		},
+		{
+			name:   "ICS",
+			format: export.FormatICS,
+			expected: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//haonguyen.tech//pomo//EN\r\n" +
+				"BEGIN:VEVENT\r\nUID:pomo-1-1709285400@haonguyen.tech\r\nDTSTAMP:20240302T080000Z\r\n" +
+				"DTSTART:20240301T093000Z\r\nDTEND:20240301T101000Z\r\nSUMMARY:Pomodoro: report\r\nDESCRIPTION:draft done\\; sent to Ann\\, Bob\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+		{name: "InvalidFormat", format: "xml", expErr: export.ErrInvalidFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := export.Write(&out, tc.format, intervals, ends, now)
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expected error %q, got %q", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestICSFolding(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	intervals := []pomodoro.Interval{{ID: 1, StartTime: start, ActualDuration: 25 * time.Minute,
+		Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone,
+		Note: strings.Repeat("a", 62) + "é" + strings.Repeat("b", 80)}}
+
+	var out bytes.Buffer
+	if err := export.ICS(&out, intervals, nil, start); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "DTEND:20240301T095500Z\r\n") {
+		t.Errorf("Expected the event to last the actual duration without an end, got %q", out.String())
+	}
+
+	// "DESCRIPTION:" takes 12 octets, the 2 octets of é would be the 75th and 76th
+	exp := "DESCRIPTION:" + strings.Repeat("a", 62) + "\r\n é" + strings.Repeat("b", 72) + "\r\n " + strings.Repeat("b", 8) + "\r\n"
+	if !strings.Contains(out.String(), exp) {
+		t.Errorf("Expected folded description %q, got %q", exp, out.String())
+	}
+	for _, line := range strings.Split(out.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines of at most 75 octets, got %d in %q", len(line), line)
+		}
+	}
+}
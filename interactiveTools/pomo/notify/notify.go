@@ -0,0 +1,180 @@
+// Package notify tells the user about the interval events: it rings the
+// terminal bell, shows a desktop notification with notify-send when
+// available and runs the shell hooks set in the configuration.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// Events of an interval
+const (
+	EventStart = "start"
+	EventEnd   = "end"
+	EventPause = "pause"
+)
+
+// desktopCommand shows the desktop notifications
+const desktopCommand = "notify-send"
+
+// Command is a command run by a notifier
+type Command struct {
+	Name  string
+	Args  []string
+	Env   []string
+	Stdin []byte
+}
+
+// Runner runs the commands of a notifier
+type Runner interface {
+	LookPath(file string) (string, error)
+	Run(ctx context.Context, c Command) error
+}
+
+// ExecRunner runs the commands as processes
+type ExecRunner struct{}
+
+func (ExecRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+func (ExecRunner) Run(ctx context.Context, c Command) error {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdin = bytes.NewReader(c.Stdin)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", c.Name, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Hook is a shell command run on an event, or on every event when Event is empty
+type Hook struct {
+	Event   string `mapstructure:"event"`
+	Command string `mapstructure:"command"`
+}
+
+// Notifier notifies the interval events. The zero value notifies nothing
+type Notifier struct {
+	// Bell receives the bell character when set, usually the terminal
+	Bell io.Writer
+	// Desktop shows desktop notifications when notify-send is available
+	Desktop bool
+	Hooks   []Hook
+	// Runner runs the commands, ExecRunner when nil
+	Runner Runner
+}
+
+// payload is the JSON description of the event sent to the hooks on stdin
+type payload struct {
+	Event          string    `json:"event"`
+	ID             int64     `json:"id"`
+	Category       string    `json:"category"`
+	State          string    `json:"state"`
+	StartTime      time.Time `json:"startTime"`
+	PlannedSeconds int       `json:"plannedSeconds"`
+	ActualSeconds  int       `json:"actualSeconds"`
+	Label          string    `json:"label,omitempty"`
+	Note           string    `json:"note,omitempty"`
+}
+
+// Notify notifies the event of the interval through every channel enabled,
+// returning the errors of all of them
+func (n *Notifier) Notify(ctx context.Context, event string, i pomodoro.Interval) error {
+	runner := n.Runner
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+
+	var errs []error
+	if n.Bell != nil {
+		if _, err := io.WriteString(n.Bell, "\a"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if n.Desktop {
+		// Skipped silently without notify-send, like on macOS
+		if path, err := runner.LookPath(desktopCommand); err == nil {
+			c := Command{Name: path, Args: []string{"pomo", Message(event, i)}}
+			if err := runner.Run(ctx, c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, h := range n.Hooks {
+		if h.Event != "" && h.Event != event {
+			continue
+		}
+		if err := runner.Run(ctx, hookCommand(h, event, i)); err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", h.Command, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hookCommand returns the command running the hook with the interval in the
+// POMO_ environment variables and as JSON on stdin
+func hookCommand(h Hook, event string, i pomodoro.Interval) Command {
+	p := payload{
+		Event:          event,
+		ID:             i.ID,
+		Category:       i.Category,
+		State:          pomodoro.StateName(i.State),
+		StartTime:      i.StartTime,
+		PlannedSeconds: int(i.PlannedDuration.Seconds()),
+		ActualSeconds:  int(i.ActualDuration.Seconds()),
+		Label:          i.Label,
+		Note:           i.Note,
+	}
+	// A struct of plain fields always encodes
+	stdin, _ := json.Marshal(p)
+
+	return Command{
+		Name: "sh",
+		Args: []string{"-c", h.Command},
+		Env: []string{
+			"POMO_EVENT=" + p.Event,
+			"POMO_ID=" + strconv.FormatInt(p.ID, 10),
+			"POMO_CATEGORY=" + p.Category,
+			"POMO_STATE=" + p.State,
+			"POMO_START_TIME=" + p.StartTime.Format(time.RFC3339),
+			"POMO_PLANNED_SECONDS=" + strconv.Itoa(p.PlannedSeconds),
+			"POMO_ACTUAL_SECONDS=" + strconv.Itoa(p.ActualSeconds),
+			"POMO_LABEL=" + p.Label,
+			"POMO_NOTE=" + p.Note,
+		},
+		Stdin: append(stdin, '\n'),
+	}
+}
+
+// Message describes the event of the interval for the user
+func Message(event string, i pomodoro.Interval) string {
+	switch event {
+	case EventStart:
+		return fmt.Sprintf("%s started, %s left", i.Category, i.PlannedDuration-i.ActualDuration)
+	case EventPause:
+		return fmt.Sprintf("%s paused, %s left", i.Category, i.PlannedDuration-i.ActualDuration)
+	case EventEnd:
+		if i.Category == pomodoro.CategoryPomodoro {
+			return "Pomodoro done, take a break"
+		}
+		return fmt.Sprintf("%s done, back to work", i.Category)
+	default:
+		return fmt.Sprintf("%s %s", i.Category, event)
+	}
+}
@@ -0,0 +1,176 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/notify"
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+// fakeRunner records the commands instead of running them
+type fakeRunner struct {
+	paths    map[string]string
+	commands []notify.Command
+	err      error
+}
+
+func (f *fakeRunner) LookPath(file string) (string, error) {
+	if p, ok := f.paths[file]; ok {
+		return p, nil
+	}
+	return "", exec.ErrNotFound
+}
+
+func (f *fakeRunner) Run(ctx context.Context, c notify.Command) error {
+	f.commands = append(f.commands, c)
+	return f.err
+}
+
+func TestNotify(t *testing.T) {
+	i := pomodoro.Interval{
+		ID:              3,
+		StartTime:       time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  25 * time.Minute,
+		Category:        pomodoro.CategoryPomodoro,
+		State:           pomodoro.StateDone,
+	}
+	hooks := []notify.Hook{
+		{Event: notify.EventEnd, Command: "on-end"},
+		{Command: "on-all"},
+		{Event: notify.EventStart, Command: "on-start"},
+	}
+
+	testCases := []struct {
+		name        string
+		desktop     bool
+		paths       map[string]string
+		runErr      error
+		expBell     string
+		expCommands []string
+		expErr      bool
+	}{
+		{
+			name:        "Desktop",
+			desktop:     true,
+			paths:       map[string]string{"notify-send": "/usr/bin/notify-send"},
+			expBell:     "\a",
+			expCommands: []string{"/usr/bin/notify-send pomo Pomodoro done, take a break", "sh -c on-end", "sh -c on-all"},
+		},
+		{
+			name:        "NoNotifySend",
+			desktop:     true,
+			expBell:     "\a",
+			expCommands: []string{"sh -c on-end", "sh -c on-all"},
+		},
+		{
+			name:        "DesktopDisabled",
+			paths:       map[string]string{"notify-send": "/usr/bin/notify-send"},
+			expBell:     "\a",
+			expCommands: []string{"sh -c on-end", "sh -c on-all"},
+		},
+		{
+			name:        "HookFails",
+			runErr:      errors.New("exit status 1"),
+			expBell:     "\a",
+			expCommands: []string{"sh -c on-end", "sh -c on-all"},
+			expErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bell bytes.Buffer
+			runner := &fakeRunner{paths: tc.paths, err: tc.runErr}
+			n := &notify.Notifier{Bell: &bell, Desktop: tc.desktop, Hooks: hooks, Runner: runner}
+
+			err := n.Notify(context.Background(), notify.EventEnd, i)
+			if tc.expErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tc.expErr, err)
+			}
+			if bell.String() != tc.expBell {
+				t.Errorf("Expected bell %q, got %q", tc.expBell, bell.String())
+			}
+
+			got := []string{}
+			for _, c := range runner.commands {
+				got = append(got, c.Name+" "+strings.Join(c.Args, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.expCommands, "\n") {
+				t.Errorf("Expected commands %q, got %q", tc.expCommands, got)
+			}
+		})
+	}
+}
+
+func TestNotifyHookInput(t *testing.T) {
+	runner := &fakeRunner{}
+	n := &notify.Notifier{Hooks: []notify.Hook{{Command: "hook"}}, Runner: runner}
+	i := pomodoro.Interval{
+		ID:              3,
+		StartTime:       time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		PlannedDuration: 5 * time.Minute,
+		ActualDuration:  2 * time.Minute,
+		Category:        pomodoro.CategoryShortBreak,
+		State:           pomodoro.StatePaused,
+		Label:           "stretch",
+	}
+
+	if err := n.Notify(context.Background(), notify.EventPause, i); err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.commands) != 1 {
+		t.Fatalf("Expected 1 command, got %d", len(runner.commands))
+	}
+	c := runner.commands[0]
+
+	expEnv := []string{
+		"POMO_EVENT=pause",
+		"POMO_ID=3",
+		"POMO_CATEGORY=ShortBreak",
+		"POMO_STATE=paused",
+		"POMO_START_TIME=2024-03-01T09:30:00Z",
+		"POMO_PLANNED_SECONDS=300",
+		"POMO_ACTUAL_SECONDS=120",
+		"POMO_LABEL=stretch",
+		"POMO_NOTE=",
+	}
+	if strings.Join(c.Env, " ") != strings.Join(expEnv, " ") {
+		t.Errorf("Expected env %q, got %q", expEnv, c.Env)
+	}
+
+	expStdin := `{"event":"pause","id":3,"category":"ShortBreak","state":"paused","startTime":"2024-03-01T09:30:00Z","plannedSeconds":300,"actualSeconds":120,"label":"stretch"}` + "\n"
+	if string(c.Stdin) != expStdin {
+		t.Errorf("Expected stdin %q, got %q", expStdin, c.Stdin)
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out := t.TempDir() + "/out"
+	n := &notify.Notifier{Hooks: []notify.Hook{{Command: `cat > "` + out + `"; test "$POMO_EVENT" = start`}}}
+	i := pomodoro.Interval{ID: 1, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateRunning}
+	if err := n.Notify(context.Background(), notify.EventStart, i); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(stdin), `{"event":"start","id":1,`) {
+		t.Errorf("Expected the interval on stdin, got %q", stdin)
+	}
+	if err := n.Notify(context.Background(), notify.EventEnd, i); err == nil {
+		t.Error("Expected the failing hook to be reported")
+	}
+}
@@ -0,0 +1,82 @@
+package pomodoro
+
+import (
+	"context"
+	"time"
+)
+
+// Countdown is called every second before an interval is started
+// automatically, with the time left
+type Countdown func(next Interval, left time.Duration)
+
+// autoStart tells if the intervals of the category start automatically
+func (c *IntervalConfig) autoStart(category string) bool {
+	if category == CategoryPomodoro {
+		return c.AutoStartPomodoros
+	}
+	return c.AutoStartBreaks
+}
+
+// StartWithCountdown starts the interval like Start. When it is done and the
+// configuration starts the next category automatically, it creates the next
+// interval and starts it once the countdown of config.AutoStartDelay is over,
+// and so on. Cancelling or skipping the next interval during the countdown
+// stops the chain
+func (i Interval) StartWithCountdown(ctx context.Context, config *IntervalConfig,
+	start, periodic, end Callback, countdown Countdown,
+) error {
+	for {
+		if err := i.Start(ctx, config, start, periodic, end); err != nil {
+			return err
+		}
+		if !config.AutoStartBreaks && !config.AutoStartPomodoros {
+			return nil
+		}
+
+		done, err := config.repo.ByID(i.ID)
+		if err != nil {
+			return err
+		}
+		if done.State != StateDone {
+			return nil
+		}
+
+		next, err := GetInterval(config)
+		if err != nil {
+			return err
+		}
+		if !config.autoStart(next.Category) {
+			return nil
+		}
+
+		if i, err = wait(ctx, config, next, countdown); err != nil || ctx.Err() != nil || i.State != StateNotStarted {
+			return err
+		}
+	}
+}
+
+// wait counts config.AutoStartDelay down by steps of a second and returns
+// the next interval as stored. The countdown stops early once ctx is done or
+// the interval was changed meanwhile, like cancelled or started by hand
+func wait(ctx context.Context, config *IntervalConfig, next Interval, countdown Countdown) (Interval, error) {
+	for left := config.AutoStartDelay; ; {
+		stored, err := config.repo.ByID(next.ID)
+		if err != nil || stored.State != StateNotStarted || left <= 0 {
+			return stored, err
+		}
+		if countdown != nil {
+			countdown(stored, left)
+		}
+
+		if ctx.Err() != nil {
+			return next, nil
+		}
+		step := min(left, time.Second)
+		select {
+		case <-ctx.Done():
+			return next, nil
+		case <-config.clock().After(step):
+		}
+		left -= step
+	}
+}
@@ -0,0 +1,82 @@
+package pomodoro
+
+import "time"
+
+// Clock tells the time and waits for it to pass. The engine takes the time
+// elapsed from the clock, a fake one lets the tests run without sleeping
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// wallClock is the Clock of the time package
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Segment is a period the interval ran, from a start or resume to the next
+// pause or end. End is zero while the interval is still running
+type Segment struct {
+	Start time.Time
+	End   time.Time
+}
+
+// elapsed returns the time the segments ran until now
+func elapsed(segments []Segment, now time.Time) time.Duration {
+	var d time.Duration
+	for _, s := range segments {
+		if s.End.IsZero() {
+			d += now.Sub(s.Start)
+			continue
+		}
+		d += s.End.Sub(s.Start)
+	}
+	return d
+}
+
+// End returns when the interval last stopped running, which is the end of
+// its last segment, or now while it runs. The intervals recorded without
+// segments end once their actual duration elapsed from their start
+func (i Interval) End(config *IntervalConfig) (time.Time, error) {
+	segments, err := config.repo.Segments(i.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(segments) == 0 {
+		return i.StartTime.Add(i.ActualDuration), nil
+	}
+	if end := segments[len(segments)-1].End; !end.IsZero() {
+		return end, nil
+	}
+	return config.clock().Now(), nil
+}
+
+// stopSegment ends the running segment of the interval, returning the time it
+// ran in total in whole seconds, like ActualDuration. A running segment ends
+// at now, or when now is unknown like after a crash, once the time last
+// recorded in ActualDuration elapsed
+func stopSegment(config *IntervalConfig, i Interval, now time.Time) (time.Duration, error) {
+	segments, err := config.repo.Segments(i.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(segments) == 0 || !segments[len(segments)-1].End.IsZero() {
+		return elapsed(segments, now).Truncate(time.Second), nil
+	}
+
+	last := &segments[len(segments)-1]
+	if now.IsZero() {
+		now = last.Start.Add(max(i.ActualDuration-elapsed(segments[:len(segments)-1], now), 0))
+	}
+	last.End = now
+	if err := config.repo.EndSegment(i.ID, now); err != nil {
+		return 0, err
+	}
+	return elapsed(segments, now).Truncate(time.Second), nil
+}
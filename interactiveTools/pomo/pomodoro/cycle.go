@@ -0,0 +1,107 @@
+package pomodoro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCycle is returned for a cycle definition that can't be used
+var ErrInvalidCycle = errors.New("invalid cycle")
+
+// DefaultCycle has a long break after every 4 pomodoros
+var DefaultCycle = []string{
+	CategoryPomodoro, CategoryShortBreak,
+	CategoryPomodoro, CategoryShortBreak,
+	CategoryPomodoro, CategoryShortBreak,
+	CategoryPomodoro, CategoryLongBreak,
+}
+
+var cycleAbbreviations = map[string]string{
+	"P": CategoryPomodoro,
+	"S": CategoryShortBreak,
+	"L": CategoryLongBreak,
+}
+
+// NewCycle returns the cycle of n pomodoros separated by short breaks and
+// ending with a long break, or with a short one when longBreak is false
+func NewCycle(n int, longBreak bool) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: %d pomodoros per cycle", ErrInvalidCycle, n)
+	}
+
+	c := []string{}
+	for k := 0; k < n; k++ {
+		c = append(c, CategoryPomodoro, CategoryShortBreak)
+	}
+	if longBreak {
+		c[len(c)-1] = CategoryLongBreak
+	}
+	return c, nil
+}
+
+// ParseCycle parses a cycle given as a comma separated list of categories,
+// abbreviated P, S and L like "P,S,P,S,P,L". It must start with a pomodoro
+// and can only have a long break at its end
+func ParseCycle(s string) ([]string, error) {
+	c := []string{}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		category, ok := cycleAbbreviations[strings.ToUpper(f)]
+		if !ok {
+			category = f
+		}
+		c = append(c, category)
+	}
+
+	return c, validateCycle(c)
+}
+
+func validateCycle(c []string) error {
+	if len(c) == 0 || c[0] != CategoryPomodoro {
+		return fmt.Errorf("%w: %v must start with a %s", ErrInvalidCycle, c, CategoryPomodoro)
+	}
+	for k, category := range c {
+		switch category {
+		case CategoryPomodoro, CategoryShortBreak:
+		case CategoryLongBreak:
+			// The cycle restarts after a long break, which is also how the
+			// databases from before CycleStart recorded a reset
+			if k != len(c)-1 {
+				return fmt.Errorf("%w: %v can only end with a %s", ErrInvalidCycle, c, CategoryLongBreak)
+			}
+		default:
+			return fmt.Errorf("%w: unknown category %q", ErrInvalidCycle, category)
+		}
+	}
+	return nil
+}
+
+// cycleNext returns the category following the recent ones, given newest
+// first, in the cycle. The position in the cycle is the longest start of the
+// cycle the recent categories end with, the cycle starting over when none does
+func cycleNext(cycle []string, recent []Interval) string {
+	n := len(recent)
+	if n > len(cycle) {
+		n = len(cycle)
+	}
+
+	for k := n; k > 0; k-- {
+		match := true
+		for j := 0; j < k; j++ {
+			// recent[k-1-j] is the j-th oldest of the last k intervals
+			if recent[k-1-j].Category != cycle[j] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if k == len(cycle) {
+			return cycle[0]
+		}
+		return cycle[k]
+	}
+	return cycle[0]
+}
@@ -0,0 +1,114 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+func TestCycle(t *testing.T) {
+	abbreviations := map[string]string{
+		pomodoro.CategoryPomodoro:   "P",
+		pomodoro.CategoryShortBreak: "S",
+		pomodoro.CategoryLongBreak:  "L",
+	}
+	mustCycle := func(c []string, err error) []string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name  string
+		cycle []string
+		exp   string
+	}{
+		{name: "Default", exp: "P,S,P,S,P,S,P,L,P,S,P,S,P,S,P,L"},
+		{name: "LongBreakEvery2", cycle: mustCycle(pomodoro.NewCycle(2, true)), exp: "P,S,P,L,P,S,P,L"},
+		{name: "NoLongBreak", cycle: mustCycle(pomodoro.NewCycle(1, false)), exp: "P,S,P,S,P,S"},
+		{name: "NoLongBreakSet", cycle: mustCycle(pomodoro.NewCycle(3, false)), exp: "P,S,P,S,P,S,P,S"},
+		{name: "Custom", cycle: mustCycle(pomodoro.ParseCycle("P,S,P,S,P,L")), exp: "P,S,P,S,P,L,P,S,P,S,P,L"},
+		{name: "CustomBackToBack", cycle: mustCycle(pomodoro.ParseCycle("P, P, ShortBreak, p, l")), exp: "P,P,S,P,L,P,P,S,P,L"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+			config := pomodoro.NewConfig(repo, 3*time.Minute, time.Minute, 2*time.Minute)
+			config.Cycle = tc.cycle
+
+			i, err := pomodoro.GetInterval(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{abbreviations[i.Category]}
+			for len(got) < strings.Count(tc.exp, ",")+1 {
+				if i, err = i.Skip(config); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, abbreviations[i.Category])
+			}
+			if strings.Join(got, ",") != tc.exp {
+				t.Errorf("Expected %s, got %s", tc.exp, strings.Join(got, ","))
+			}
+
+			// A reset restarts the cycle
+			if i, err = pomodoro.ResetCycle(config); err != nil {
+				t.Fatal(err)
+			}
+			if i, err = i.Skip(config); err != nil {
+				t.Fatal(err)
+			}
+			cycle := config.Cycle
+			if cycle == nil {
+				cycle = pomodoro.DefaultCycle
+			}
+			if i.Category != cycle[1] {
+				t.Errorf("Expected %q after a reset, got %q", cycle[1], i.Category)
+			}
+		})
+	}
+}
+
+func TestParseCycle(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		exp    []string
+		expErr bool
+	}{
+		{name: "Abbreviated", input: "P,S,P,L", exp: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro, pomodoro.CategoryLongBreak}},
+		{name: "Names", input: "Pomodoro, ShortBreak", exp: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak}},
+		{name: "Empty", input: "", expErr: true},
+		{name: "StartWithBreak", input: "S,P", expErr: true},
+		{name: "LongBreakInside", input: "P,L,P,S", expErr: true},
+		{name: "Unknown", input: "P,X", expErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := pomodoro.ParseCycle(tc.input)
+			if tc.expErr {
+				if !errors.Is(err, pomodoro.ErrInvalidCycle) {
+					t.Errorf("Expected error %q, got %q", pomodoro.ErrInvalidCycle, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(c, ",") != strings.Join(tc.exp, ",") {
+				t.Errorf("Expected %v, got %v", tc.exp, c)
+			}
+		})
+	}
+
+	if _, err := pomodoro.NewCycle(0, true); !errors.Is(err, pomodoro.ErrInvalidCycle) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrInvalidCycle, err)
+	}
+}
@@ -30,6 +30,11 @@ type Interval struct {
 	ActualDuration  time.Duration
 	Category        string
 	State           int
+	// Label is what the pomodoro is spent on and Note what came out of it
+	Label string
+	Note  string
+	// CycleStart marks the pomodoro the cycle started over from after a reset
+	CycleStart bool
 }
 
 type IntervalConfig struct {
@@ -37,14 +42,59 @@ type IntervalConfig struct {
 	PomodoroDuration   time.Duration
 	ShortBreakDuration time.Duration
 	LongBreakDuration  time.Duration
+	// Cycle is the sequence of categories the intervals follow, DefaultCycle when empty
+	Cycle []string
+	// AutoStartBreaks and AutoStartPomodoros start the next interval of the
+	// category when the previous one is done, after AutoStartDelay
+	AutoStartBreaks    bool
+	AutoStartPomodoros bool
+	AutoStartDelay     time.Duration
+	// Clock times the intervals, the wall clock when nil
+	Clock Clock
+}
+
+func (c *IntervalConfig) cycle() []string {
+	if len(c.Cycle) == 0 {
+		return DefaultCycle
+	}
+	return c.Cycle
+}
+
+func (c *IntervalConfig) clock() Clock {
+	if c.Clock == nil {
+		return wallClock{}
+	}
+	return c.Clock
 }
 
 type Repository interface {
 	Create(i Interval) (int64, error)
+	// Update writes the start time, actual duration and state of the
+	// interval, the other fields are set on creation
 	Update(i Interval) error
+	// UpdateProgress sets the actual duration of the interval only while it
+	// is running, returning ErrIntervalNotRunning once it was paused or
+	// stopped, maybe by another process sharing the repository
+	UpdateProgress(id int64, d time.Duration) error
 	ByID(id int64) (Interval, error)
 	Last() (Interval, error)
-	Breaks(n int) ([]Interval, error)
+	// LastPomodoro returns the most recent pomodoro done or cancelled, or
+	// ErrNoIntervals
+	LastPomodoro() (Interval, error)
+	// Recent returns the last n intervals, newest first
+	Recent(n int) ([]Interval, error)
+	// Range returns the intervals started in [start, end), oldest first
+	Range(start, end time.Time) ([]Interval, error)
+	// Segments returns the periods the interval ran, oldest first
+	Segments(id int64) ([]Segment, error)
+	// StartSegment records the interval running from start, until
+	// EndSegment records the end of the running segment
+	StartSegment(id int64, start time.Time) error
+	EndSegment(id int64, end time.Time) error
+	// SetLabel and SetNote change the label and note of the interval, which
+	// Update leaves as they are so it doesn't undo a change made meanwhile
+	SetLabel(id int64, label string) error
+	SetNote(id int64, note string) error
 }
 
 var (
@@ -53,8 +103,25 @@ var (
 	ErrIntervalCompleted  = errors.New("interval is completed or canceled")
 	ErrInvalidState       = errors.New("invalid state")
 	ErrInvalidID          = errors.New("invalid ID")
+	ErrIntervalRunning    = errors.New("interval already running")
 )
 
+var stateNames = map[int]string{
+	StateNotStarted: "not started",
+	StateRunning:    "running",
+	StatePaused:     "paused",
+	StateDone:       "done",
+	StateCancelled:  "cancelled",
+}
+
+// StateName returns the name of the state, like "running"
+func StateName(state int) string {
+	if n, ok := stateNames[state]; ok {
+		return n
+	}
+	return fmt.Sprintf("state %d", state)
+}
+
 func NewConfig(repo Repository, pomodoro, shortBreak, longBreak time.Duration) *IntervalConfig {
 	c := &IntervalConfig{
 		repo:               repo,
@@ -75,94 +142,103 @@ func NewConfig(repo Repository, pomodoro, shortBreak, longBreak time.Duration) *
 	return c
 }
 
-// nextCategory From the Repository determine the NextCategory
-func nextCategory(r Repository) (string, error) {
-	li, err := r.Last()
-	if err != nil && err == ErrNoIntervals {
-		return CategoryPomodoro, nil
-	}
-	if err != nil {
-		return "", err
-	}
-
-	if li.Category == CategoryLongBreak || li.Category == CategoryShortBreak {
-		return CategoryPomodoro, nil
-	}
-
-	lastBreaks, err := r.Breaks(3)
+// nextCategory From the Repository determine the NextCategory following the cycle
+func nextCategory(r Repository, cycle []string) (string, error) {
+	recent, err := r.Recent(len(cycle))
 	if err != nil {
 		return "", err
 	}
-
-	if len(lastBreaks) < 3 {
-		return CategoryShortBreak, nil
-	}
-
-	for _, i := range lastBreaks {
-		if i.Category == CategoryLongBreak {
-			return CategoryShortBreak, nil
+	// The intervals before a reset don't count
+	for k, i := range recent {
+		if i.CycleStart {
+			recent = recent[:k+1]
+			break
 		}
 	}
 
-	return CategoryLongBreak, nil
+	return cycleNext(cycle, recent), nil
 }
 
 type Callback func(Interval)
 
+// tick runs the interval until it is done, paused or cancelled. The time
+// elapsed comes from the segments recorded rather than from counting the
+// ticks, so it doesn't drift when an update is slow or the computer sleeps
 func tick(ctx context.Context, id int64, config *IntervalConfig, start, periodic, end Callback) error {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	i, err := config.repo.ByID(id)
-	if err != nil {
-		return err
-	}
-
-	// creates a timer channel using the time.After function.
-	// The time.After function returns a channel that will send the current time after a specified duration.
-	// In this case, the duration is calculated as i.PlannedDuration - i.ActualDuration.
-	expire := time.After(i.PlannedDuration - i.ActualDuration)
+	clock := config.clock()
 
-	start(i)
+	for started := false; ; started = true {
+		i, err := config.repo.ByID(id)
+		if err != nil {
+			return err
+		}
+		// Paused or stopped, maybe by another process sharing the repository
+		if i.State != StateRunning {
+			return nil
+		}
 
-	for {
-		select {
-		// For every tick
-		case <-ticker.C:
-			i, err := config.repo.ByID(id)
-			if err != nil {
+		now := clock.Now()
+		if ctx.Err() != nil {
+			if i.ActualDuration, err = stopSegment(config, i, now); err != nil {
 				return err
 			}
-			if i.State == StatePaused {
-				return nil
-			}
-			i.ActualDuration += time.Second
-			if err := config.repo.Update(i); err != nil {
+			i.State = StateCancelled
+			return config.repo.Update(i)
+		}
+
+		segments, err := config.repo.Segments(id)
+		if err != nil {
+			return err
+		}
+		d := elapsed(segments, now)
+		if d >= i.PlannedDuration {
+			// The segment ends when the interval was due rather than when noticed
+			if err := config.repo.EndSegment(id, now.Add(i.PlannedDuration-d)); err != nil {
+				// No running segment left, another process paused or stopped
+				// the interval since its segments were read
+				if i, rerr := config.repo.ByID(id); rerr == nil && i.State != StateRunning {
+					return nil
+				}
 				return err
 			}
-			periodic(i)
-		case <-expire:
-			i, err := config.repo.ByID(id)
-			if err != nil {
+			i.ActualDuration = i.PlannedDuration
+			i.State = StateDone
+			if err := config.repo.Update(i); err != nil {
 				return err
 			}
-			i.State = StateDone
 			end(i)
-			return config.repo.Update(i)
-		case <-ctx.Done():
-			i, err := config.repo.ByID(id)
-			if err != nil {
-				return err
+			return nil
+		}
+
+		// Only the duration is written, not to undo a pause or stop made
+		// since the interval was read
+		i.ActualDuration = d.Truncate(time.Second)
+		if err := config.repo.UpdateProgress(id, i.ActualDuration); err != nil {
+			if errors.Is(err, ErrIntervalNotRunning) {
+				return nil
+			}
+			return err
+		}
+		if started {
+			periodic(i)
+		} else {
+			start(i)
+		}
+
+		// Wake up on the next second elapsed, or when the interval is due
+		wait := min(time.Second-d%time.Second, i.PlannedDuration-d)
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case <-clock.After(wait):
 			}
-			i.State = StateCancelled
-			return config.repo.Update(i)
 		}
 	}
 }
 
 func newInterval(config *IntervalConfig) (Interval, error) {
 	i := Interval{}
-	category, err := nextCategory(config.repo)
+	category, err := nextCategory(config.repo, config.cycle())
 	if err != nil {
 		return i, err
 	}
@@ -202,14 +278,18 @@ func GetInterval(config *IntervalConfig) (Interval, error) {
 func (i Interval) Start(ctx context.Context, config *IntervalConfig,
 	start, periodic, end Callback,
 ) error {
+	now := config.clock().Now()
 	switch i.State {
 	case StateRunning:
 		return nil
 	case StateNotStarted:
-		i.StartTime = time.Now()
+		i.StartTime = now
 		fallthrough
 	case StatePaused:
 		i.State = StateRunning
+		if err := config.repo.StartSegment(i.ID, now); err != nil {
+			return err
+		}
 		if err := config.repo.Update(i); err != nil {
 			return err
 		}
@@ -226,6 +306,132 @@ func (i Interval) Pause(config *IntervalConfig) error {
 		return ErrIntervalNotRunning
 	}
 
+	d, err := stopSegment(config, i, config.clock().Now())
+	if err != nil {
+		return err
+	}
+	i.ActualDuration = d
 	i.State = StatePaused
 	return config.repo.Update(i)
 }
+
+// Cancel stops the interval for good. A running interval stops on its next tick
+func (i Interval) Cancel(config *IntervalConfig) error {
+	switch i.State {
+	case StateRunning:
+		d, err := stopSegment(config, i, config.clock().Now())
+		if err != nil {
+			return err
+		}
+		i.ActualDuration = d
+		fallthrough
+	case StateNotStarted, StatePaused:
+		i.State = StateCancelled
+		return config.repo.Update(i)
+	case StateCancelled, StateDone:
+		return fmt.Errorf("%w: cannot cancel", ErrIntervalCompleted)
+	default:
+		return fmt.Errorf("%w, :%d", ErrInvalidState, i.State)
+	}
+}
+
+// Skip cancels the interval when unfinished and returns the next one, not
+// started. The skipped interval isn't recorded as done but still counts in
+// the cycle
+func (i Interval) Skip(config *IntervalConfig) (Interval, error) {
+	if i.State != StateDone && i.State != StateCancelled {
+		if err := i.Cancel(config); err != nil {
+			return Interval{}, err
+		}
+	}
+	return newInterval(config)
+}
+
+// ResetCycle cancels the last interval when unfinished and starts the cycle
+// over, returning a new Pomodoro not started marked as the cycle start
+func ResetCycle(config *IntervalConfig) (Interval, error) {
+	last, err := config.repo.Last()
+	if err != nil && err != ErrNoIntervals {
+		return Interval{}, err
+	}
+	if err == nil && last.State != StateDone && last.State != StateCancelled {
+		if err := last.Cancel(config); err != nil {
+			return Interval{}, err
+		}
+	}
+
+	i := Interval{
+		PlannedDuration: config.PomodoroDuration,
+		Category:        CategoryPomodoro,
+		CycleStart:      true,
+	}
+	if i.ID, err = config.repo.Create(i); err != nil {
+		return Interval{}, err
+	}
+	return i, nil
+}
+
+// Suspend pauses the last interval when it is running, so it can be resumed
+// by the next session instead of being cancelled
+func Suspend(config *IntervalConfig) error {
+	i, err := config.repo.Last()
+	if err == ErrNoIntervals {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if i.State != StateRunning {
+		return nil
+	}
+
+	return i.Pause(config)
+}
+
+// Resume returns the interval the last session left started but unfinished,
+// paused so it can be started again, or ErrNoIntervals when there is none.
+// An interval still running was left by a session that didn't exit cleanly
+func Resume(config *IntervalConfig) (Interval, error) {
+	i, err := config.repo.Last()
+	if err != nil {
+		return i, err
+	}
+
+	switch i.State {
+	case StatePaused:
+		return i, nil
+	case StateRunning:
+		// The segment running ends with the time last recorded
+		if i.ActualDuration, err = stopSegment(config, i, time.Time{}); err != nil {
+			return i, err
+		}
+		i.State = StatePaused
+		return i, config.repo.Update(i)
+	default:
+		return Interval{}, ErrNoIntervals
+	}
+}
+
+// Last returns the most recent interval, or ErrNoIntervals
+func Last(config *IntervalConfig) (Interval, error) {
+	return config.repo.Last()
+}
+
+// History returns the intervals started in [start, end), oldest first
+func History(config *IntervalConfig, start, end time.Time) ([]Interval, error) {
+	return config.repo.Range(start, end)
+}
+
+// Stop cancels the last interval when it is running or paused. The process
+// running it notices on its next tick
+func Stop(config *IntervalConfig) (Interval, error) {
+	i, err := config.repo.Last()
+	if err != nil {
+		return i, err
+	}
+	if i.State != StateRunning && i.State != StatePaused {
+		return i, ErrIntervalNotRunning
+	}
+
+	return i, i.Cancel(config)
+}
@@ -4,12 +4,44 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
 
+// fakeClock is a Clock moving forward only when waited for or advanced
+type fakeClock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// After moves the clock forward by d right away
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) time.Time {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
 func TestNewConfig(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -121,6 +153,7 @@ func TestPause(t *testing.T) {
 	defer cleanup()
 
 	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	config.Clock = newFakeClock()
 	testsCases := []struct {
 		name        string
 		start       bool
@@ -209,6 +242,7 @@ func TestStart(t *testing.T) {
 	}
 
 	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	config.Clock = newFakeClock()
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
@@ -257,3 +291,604 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestResume(t *testing.T) {
+	const duration = 2 * time.Second
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	config.Clock = newFakeClock()
+	if _, err := pomodoro.Resume(config); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Fatalf("Expected error %q, got %q", pomodoro.ErrNoIntervals, err)
+	}
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pomodoro.Resume(config); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q for an interval not started, got %q", pomodoro.ErrNoIntervals, err)
+	}
+
+	// Quit the app while the interval is running
+	ctx, cancel := context.WithCancel(context.Background())
+	noop := func(pomodoro.Interval) {}
+	periodic := func(pomodoro.Interval) {
+		if err := pomodoro.Suspend(config); err != nil {
+			t.Error(err)
+		}
+		cancel()
+	}
+	end := func(pomodoro.Interval) {
+		t.Errorf("End callback should not be executed")
+	}
+	if err := i.Start(ctx, config, noop, periodic, end); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = pomodoro.Resume(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d\n", pomodoro.StatePaused, i.State)
+	}
+	if i.ActualDuration != duration/2 {
+		t.Errorf("Expected ActualDuration %q, got %q\n", duration/2, i.ActualDuration)
+	}
+
+	// A session ending without suspending leaves the interval running
+	i.State = pomodoro.StateRunning
+	if err := repo.Update(i); err != nil {
+		t.Fatal(err)
+	}
+	i, err = pomodoro.Resume(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d\n", pomodoro.StatePaused, i.State)
+	}
+
+	if err := i.Start(context.Background(), config, noop, noop, noop); err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateDone || i.ActualDuration != duration {
+		t.Errorf("Expected the resumed interval to finish, got %v", i)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, time.Minute, time.Minute, time.Minute)
+
+	testCases := []struct {
+		name     string
+		state    int
+		expError error
+	}{
+		{name: "NotStarted", state: pomodoro.StateNotStarted},
+		{name: "Running", state: pomodoro.StateRunning},
+		{name: "Paused", state: pomodoro.StatePaused},
+		{name: "Done", state: pomodoro.StateDone, expError: pomodoro.ErrIntervalCompleted},
+		{name: "Cancelled", state: pomodoro.StateCancelled, expError: pomodoro.ErrIntervalCompleted},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			i := pomodoro.Interval{Category: pomodoro.CategoryPomodoro, PlannedDuration: time.Minute, State: tc.state}
+			id, err := repo.Create(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i, err = repo.ByID(id); err != nil {
+				t.Fatal(err)
+			}
+
+			err = i.Cancel(config)
+			if tc.expError != nil {
+				if !errors.Is(err, tc.expError) {
+					t.Errorf("Expected error %q, got %q", tc.expError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i, err = repo.ByID(id); err != nil {
+				t.Fatal(err)
+			}
+			if i.State != pomodoro.StateCancelled {
+				t.Errorf("Expected state %d, got %d\n", pomodoro.StateCancelled, i.State)
+			}
+		})
+	}
+}
+
+func TestCancelRunning(t *testing.T) {
+	const duration = 2 * time.Second
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	config.Clock = newFakeClock()
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(pomodoro.Interval) {}
+	periodic := func(i pomodoro.Interval) {
+		if err := i.Cancel(config); err != nil {
+			t.Error(err)
+		}
+	}
+	end := func(pomodoro.Interval) {
+		t.Errorf("End callback should not be executed")
+	}
+	if err := i.Start(context.Background(), config, noop, periodic, end); err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateCancelled {
+		t.Errorf("Expected state %d, got %d\n", pomodoro.StateCancelled, i.State)
+	}
+}
+
+// pausingRepo pauses the interval through another config, like another pomo
+// process would, after the tick read it and before it writes its progress
+type pausingRepo struct {
+	pomodoro.Repository
+	other  *pomodoro.IntervalConfig
+	writes int
+}
+
+func (r *pausingRepo) UpdateProgress(id int64, d time.Duration) error {
+	if r.writes++; r.writes == 2 {
+		i, err := r.Repository.ByID(id)
+		if err != nil {
+			return err
+		}
+		if err := i.Pause(r.other); err != nil {
+			return err
+		}
+	}
+	return r.Repository.UpdateProgress(id, d)
+}
+
+func TestPauseDuringTick(t *testing.T) {
+	const duration = 3 * time.Second
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	clock := newFakeClock()
+
+	other := pomodoro.NewConfig(repo, duration, duration, duration)
+	other.Clock = clock
+	config := pomodoro.NewConfig(&pausingRepo{Repository: repo, other: other}, duration, duration, duration)
+	config.Clock = clock
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(pomodoro.Interval) {}
+	end := func(pomodoro.Interval) {
+		t.Errorf("End callback should not be executed")
+	}
+	if err := i.Start(context.Background(), config, noop, noop, end); err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d\n", pomodoro.StatePaused, i.State)
+	}
+	if i.ActualDuration != time.Second {
+		t.Errorf("Expected %q, got %q\n", time.Second, i.ActualDuration)
+	}
+	if err := repo.UpdateProgress(i.ID, duration); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrIntervalNotRunning, err)
+	}
+}
+
+// finishPausingRepo pauses the interval through another config after the
+// tick read its segments and before it ends the running one
+type finishPausingRepo struct {
+	pomodoro.Repository
+	other *pomodoro.IntervalConfig
+}
+
+func (r *finishPausingRepo) EndSegment(id int64, end time.Time) error {
+	i, err := r.Repository.ByID(id)
+	if err != nil {
+		return err
+	}
+	if err := i.Pause(r.other); err != nil {
+		return err
+	}
+	return r.Repository.EndSegment(id, end)
+}
+
+func TestPauseDuringFinish(t *testing.T) {
+	const duration = 3 * time.Second
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	clock := newFakeClock()
+
+	other := pomodoro.NewConfig(repo, duration, duration, duration)
+	other.Clock = clock
+	config := pomodoro.NewConfig(&finishPausingRepo{Repository: repo, other: other}, duration, duration, duration)
+	config.Clock = clock
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(pomodoro.Interval) {}
+	end := func(pomodoro.Interval) {
+		t.Errorf("End callback should not be executed")
+	}
+	if err := i.Start(context.Background(), config, noop, noop, end); err != nil {
+		t.Fatalf("Expected no error, got %q", err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d\n", pomodoro.StatePaused, i.State)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 3*time.Minute, time.Minute, 2*time.Minute)
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expCategories := []string{
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryLongBreak, pomodoro.CategoryPomodoro,
+	}
+	for _, exp := range expCategories {
+		skipped := i
+		if i, err = i.Skip(config); err != nil {
+			t.Fatal(err)
+		}
+		if i.Category != exp {
+			t.Errorf("Expected category %q, got %q\n", exp, i.Category)
+		}
+		if i.State != pomodoro.StateNotStarted {
+			t.Errorf("Expected state %d, got %d\n", pomodoro.StateNotStarted, i.State)
+		}
+		if skipped, err = repo.ByID(skipped.ID); err != nil {
+			t.Fatal(err)
+		}
+		if skipped.State != pomodoro.StateCancelled {
+			t.Errorf("Expected skipped state %d, got %d\n", pomodoro.StateCancelled, skipped.State)
+		}
+	}
+}
+
+func TestResetCycle(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 3*time.Minute, time.Minute, 2*time.Minute)
+
+	// Reset before any interval
+	i, err := pomodoro.ResetCycle(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Category != pomodoro.CategoryPomodoro {
+		t.Errorf("Expected category %q, got %q\n", pomodoro.CategoryPomodoro, i.Category)
+	}
+
+	// Two short breaks into the cycle, reset during the third pomodoro
+	for k := 0; k < 4; k++ {
+		if i, err = i.Skip(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if i, err = pomodoro.ResetCycle(config); err != nil {
+		t.Fatal(err)
+	}
+	if !i.CycleStart {
+		t.Error("Expected the reset pomodoro to start the cycle")
+	}
+
+	// The reset records no interval of its own
+	recent, err := repo.Recent(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range recent[1:] {
+		if r.Category == pomodoro.CategoryLongBreak {
+			t.Errorf("Expected no long break recorded for the reset, got %v", r)
+		}
+	}
+	if recent[1].Category != pomodoro.CategoryPomodoro || recent[1].State != pomodoro.StateCancelled {
+		t.Errorf("Expected the running pomodoro cancelled, got %v", recent[1])
+	}
+
+	expCategories := []string{
+		pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryLongBreak,
+	}
+	for k, exp := range expCategories {
+		if k > 0 {
+			if i, err = i.Skip(config); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i.Category != exp {
+			t.Errorf("Expected category %q at step %d, got %q\n", exp, k, i.Category)
+		}
+	}
+}
+
+func TestStartWithCountdown(t *testing.T) {
+	const duration = time.Second
+
+	testCases := []struct {
+		name          string
+		breaks        bool
+		pomodoros     bool
+		cancelNext    bool
+		cancelCtx     bool
+		expCategories []string
+		expStates     []int
+		expCountdowns []time.Duration
+	}{
+		{
+			name:          "NoAutoStart",
+			expCategories: []string{pomodoro.CategoryPomodoro},
+			expStates:     []int{pomodoro.StateDone},
+		},
+		{
+			name: "AutoStartBreaks", breaks: true,
+			expCategories: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro},
+			expStates:     []int{pomodoro.StateDone, pomodoro.StateDone, pomodoro.StateNotStarted},
+			expCountdowns: []time.Duration{1500 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name: "AutoStartPomodoros", pomodoros: true,
+			expCategories: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak},
+			expStates:     []int{pomodoro.StateDone, pomodoro.StateNotStarted},
+		},
+		{
+			name: "CancelDuringCountdown", breaks: true, pomodoros: true, cancelNext: true,
+			expCategories: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak},
+			expStates:     []int{pomodoro.StateDone, pomodoro.StateCancelled},
+			expCountdowns: []time.Duration{1500 * time.Millisecond},
+		},
+		{
+			name: "QuitDuringCountdown", breaks: true, pomodoros: true, cancelCtx: true,
+			expCategories: []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak},
+			expStates:     []int{pomodoro.StateDone, pomodoro.StateNotStarted},
+			expCountdowns: []time.Duration{1500 * time.Millisecond},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+			config := pomodoro.NewConfig(repo, duration, duration, duration)
+			config.Clock = newFakeClock()
+			config.AutoStartBreaks = tc.breaks
+			config.AutoStartPomodoros = tc.pomodoros
+			config.AutoStartDelay = 1500 * time.Millisecond
+
+			i, err := pomodoro.GetInterval(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var countdowns []time.Duration
+			countdown := func(next pomodoro.Interval, left time.Duration) {
+				countdowns = append(countdowns, left)
+				if tc.cancelCtx {
+					cancel()
+				}
+				if tc.cancelNext {
+					if err := next.Cancel(config); err != nil {
+						t.Error(err)
+					}
+				}
+			}
+			noop := func(pomodoro.Interval) {}
+			if err := i.StartWithCountdown(ctx, config, noop, noop, noop, countdown); err != nil {
+				t.Fatal(err)
+			}
+
+			for k, exp := range tc.expCategories {
+				i, err := repo.ByID(int64(k + 1))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i.Category != exp {
+					t.Errorf("Expected interval %d category %q, got %q\n", k+1, exp, i.Category)
+				}
+				if i.State != tc.expStates[k] {
+					t.Errorf("Expected interval %d state %d, got %d\n", k+1, tc.expStates[k], i.State)
+				}
+			}
+			if _, err := repo.ByID(int64(len(tc.expCategories) + 1)); err == nil {
+				t.Errorf("Expected %d intervals only", len(tc.expCategories))
+			}
+			if !slices.Equal(countdowns, tc.expCountdowns) {
+				t.Errorf("Expected countdowns %v, got %v\n", tc.expCountdowns, countdowns)
+			}
+		})
+	}
+}
+
+func TestWallClock(t *testing.T) {
+	const duration = 25 * time.Minute
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	clock := newFakeClock()
+	config.Clock = clock
+	t0 := clock.Now()
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(pomodoro.Interval) {}
+	end := func(pomodoro.Interval) {
+		t.Errorf("End callback should not be executed")
+	}
+	pause := func(i pomodoro.Interval) {
+		if err := i.Pause(config); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := i.Start(context.Background(), config, noop, pause, end); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paused for an hour, then the computer sleeps half an hour while running
+	resumed := clock.Advance(time.Hour)
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	sleep := func(pomodoro.Interval) {
+		clock.Advance(30 * time.Minute)
+	}
+	var ends int
+	if err := i.Start(context.Background(), config, noop, sleep, func(pomodoro.Interval) { ends++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateDone || i.ActualDuration != duration || ends != 1 {
+		t.Errorf("Expected the interval done once after %q, got %v after %d ends", duration, i, ends)
+	}
+	if !i.StartTime.Equal(t0) {
+		t.Errorf("Expected start time %q, got %q", t0, i.StartTime)
+	}
+
+	segments, err := repo.Segments(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expSegments := []pomodoro.Segment{
+		{Start: t0, End: t0.Add(time.Second)},
+		{Start: resumed, End: resumed.Add(duration - time.Second)},
+	}
+	if len(segments) != len(expSegments) {
+		t.Fatalf("Expected segments %v, got %v", expSegments, segments)
+	}
+	for k, s := range segments {
+		if !s.Start.Equal(expSegments[k].Start) || !s.End.Equal(expSegments[k].End) {
+			t.Errorf("Expected segment %v, got %v", expSegments[k], s)
+		}
+	}
+
+	// A session crashing leaves a segment running, ending with the last time recorded
+	i, err = pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StartSegment(i.ID, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	i.State = pomodoro.StateRunning
+	i.ActualDuration = 3 * time.Second
+	if err := repo.Update(i); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if i, err = pomodoro.Resume(config); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePaused || i.ActualDuration != 3*time.Second {
+		t.Errorf("Expected the interval paused after 3s, got %v", i)
+	}
+	if segments, err = repo.Segments(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 || segments[0].End.Sub(segments[0].Start) != 3*time.Second {
+		t.Errorf("Expected one segment of 3s, got %v", segments)
+	}
+}
+
+func TestIntervalEnd(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 25*time.Minute, 5*time.Minute, 15*time.Minute)
+	clock := newFakeClock()
+	config.Clock = clock
+	start := clock.Now()
+
+	i := pomodoro.Interval{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 10 * time.Minute,
+		Category: pomodoro.CategoryPomodoro, State: pomodoro.StatePaused}
+	id, err := repo.Create(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.ID = id
+
+	// Recorded without segments
+	end, err := i.End(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !end.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("Expected end %s, got %s", start.Add(10*time.Minute), end)
+	}
+
+	// Ran 5 minutes, paused 15, ran 5 more
+	if err := repo.StartSegment(id, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.EndSegment(id, start.Add(5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StartSegment(id, start.Add(20*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.EndSegment(id, start.Add(25*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if end, err = i.End(config); err != nil {
+		t.Fatal(err)
+	}
+	if !end.Equal(start.Add(25 * time.Minute)) {
+		t.Errorf("Expected end %s, got %s", start.Add(25*time.Minute), end)
+	}
+
+	// Running again, it ends now
+	if err := repo.StartSegment(id, start.Add(40*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(45 * time.Minute)
+	if end, err = i.End(config); err != nil {
+		t.Fatal(err)
+	}
+	if !end.Equal(clock.Now()) {
+		t.Errorf("Expected end %s, got %s", clock.Now(), end)
+	}
+}
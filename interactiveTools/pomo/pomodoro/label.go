@@ -0,0 +1,97 @@
+package pomodoro
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrNotPomodoro = errors.New("not a pomodoro")
+
+// SetLabel records what the pomodoro is spent on
+func (i Interval) SetLabel(config *IntervalConfig, label string) error {
+	if i.Category != CategoryPomodoro {
+		return fmt.Errorf("%w: cannot label a %s", ErrNotPomodoro, i.Category)
+	}
+	return config.repo.SetLabel(i.ID, strings.TrimSpace(label))
+}
+
+// SetNote records a note on the pomodoro, usually once it is over
+func (i Interval) SetNote(config *IntervalConfig, note string) error {
+	if i.Category != CategoryPomodoro {
+		return fmt.Errorf("%w: cannot note a %s", ErrNotPomodoro, i.Category)
+	}
+	return config.repo.SetNote(i.ID, strings.TrimSpace(note))
+}
+
+// LastPomodoro returns the most recent pomodoro done or cancelled, or ErrNoIntervals
+func LastPomodoro(config *IntervalConfig) (Interval, error) {
+	return config.repo.LastPomodoro()
+}
+
+// Annotate labels the current or next interval with text when it is a
+// pomodoro, or during a break, notes text on the pomodoro just over. It
+// returns the interval annotated with its label or note set
+func Annotate(config *IntervalConfig, text string) (Interval, error) {
+	i, err := GetInterval(config)
+	if err != nil {
+		return i, err
+	}
+	if i.Category == CategoryPomodoro {
+		i.Label = strings.TrimSpace(text)
+		return i, i.SetLabel(config, text)
+	}
+
+	if i, err = LastPomodoro(config); err != nil {
+		return i, err
+	}
+	i.Note = strings.TrimSpace(text)
+	return i, i.SetNote(config, text)
+}
+
+// LabelTotal is the focus spent on a label
+type LabelTotal struct {
+	Label string
+	// Pomodoros is the number of pomodoros done
+	Pomodoros int
+	// Focus is the time spent on all of them, cancelled ones included
+	Focus time.Duration
+}
+
+// LabelSummary totals the pomodoros started in [start, end) by label, the
+// most focused on first. The pomodoros without label are totalled under ""
+func LabelSummary(config *IntervalConfig, start, end time.Time) ([]LabelTotal, error) {
+	intervals, err := config.repo.Range(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := []LabelTotal{}
+	index := map[string]int{}
+	for _, i := range intervals {
+		if i.Category != CategoryPomodoro || i.State == StateNotStarted {
+			continue
+		}
+		k, ok := index[i.Label]
+		if !ok {
+			k = len(totals)
+			index[i.Label] = k
+			totals = append(totals, LabelTotal{Label: i.Label})
+		}
+		if i.State == StateDone {
+			totals[k].Pomodoros++
+		}
+		totals[k].Focus += i.ActualDuration
+	}
+
+	slices.SortStableFunc(totals, func(a, b LabelTotal) int {
+		if c := cmp.Compare(b.Focus, a.Focus); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Label, b.Label)
+	})
+	return totals, nil
+}
@@ -0,0 +1,179 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+func TestAnnotate(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 3*time.Minute, time.Minute, 2*time.Minute)
+
+	// Any pomodoro takes a note, but none is over yet
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.SetNote(config, "note"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pomodoro.LastPomodoro(config); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrNoIntervals, err)
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expID    int64
+		expLabel string
+		expNote  string
+	}{
+		{name: "LabelNext", text: " write report ", expID: 1, expLabel: "write report", expNote: "note"},
+		{name: "Relabel", text: "review PR", expID: 1, expLabel: "review PR", expNote: "note"},
+		{name: "NoteDuringBreak", text: "done, PR merged", expID: 1, expLabel: "review PR", expNote: "done, PR merged"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.name == "NoteDuringBreak" {
+				i, err := repo.ByID(1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				i.State = pomodoro.StateDone
+				if err := repo.Update(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			annotated, err := pomodoro.Annotate(config, tc.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			i, err := repo.ByID(tc.expID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if annotated.ID != tc.expID || i.Label != tc.expLabel || i.Note != tc.expNote {
+				t.Errorf("Expected interval %d labelled %q noted %q, got %v", tc.expID, tc.expLabel, tc.expNote, i)
+			}
+			if annotated.Label != i.Label || annotated.Note != i.Note {
+				t.Errorf("Expected the interval annotated returned, got %v", annotated)
+			}
+		})
+	}
+
+	// Only pomodoros get a label
+	i, err = pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.SetLabel(config, "rest"); !errors.Is(err, pomodoro.ErrNotPomodoro) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrNotPomodoro, err)
+	}
+}
+
+func TestLabelSummary(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	intervals := []pomodoro.Interval{
+		{StartTime: day.Add(9 * time.Hour), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Label: "report"},
+		{StartTime: day.Add(10 * time.Hour), ActualDuration: 5 * time.Minute, Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+		{StartTime: day.Add(11 * time.Hour), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Label: "email"},
+		{StartTime: day.Add(12 * time.Hour), ActualDuration: 10 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateCancelled, Label: "report"},
+		{StartTime: day.Add(13 * time.Hour), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		// Next day
+		{StartTime: day.Add(33 * time.Hour), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Label: "email"},
+	}
+	for _, i := range intervals {
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	totals, err := pomodoro.LabelSummary(config, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expTotals := []pomodoro.LabelTotal{
+		{Label: "report", Pomodoros: 1, Focus: 35 * time.Minute},
+		{Label: "", Pomodoros: 1, Focus: 25 * time.Minute},
+		{Label: "email", Pomodoros: 1, Focus: 25 * time.Minute},
+	}
+	if !slices.Equal(totals, expTotals) {
+		t.Errorf("Expected %v, got %v", expTotals, totals)
+	}
+}
+
+func TestUpdateKeepsFields(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	id, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro, PlannedDuration: time.Minute, State: pomodoro.StateNotStarted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetLabel(id, "label"); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Truncate(time.Second)
+	i := pomodoro.Interval{ID: id, StartTime: start, PlannedDuration: time.Hour, ActualDuration: time.Second,
+		Category: pomodoro.CategoryLongBreak, State: pomodoro.StateRunning, CycleStart: true}
+	if err := repo.Update(i); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.ByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.StartTime.Equal(start) || got.ActualDuration != time.Second || got.State != pomodoro.StateRunning {
+		t.Errorf("Expected start time, duration and state updated, got %+v", got)
+	}
+	if got.PlannedDuration != time.Minute || got.Category != pomodoro.CategoryPomodoro || got.CycleStart || got.Label != "label" {
+		t.Errorf("Expected the other fields kept, got %+v", got)
+	}
+}
+
+func TestLastPomodoro(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 3*time.Minute, time.Minute, 2*time.Minute)
+
+	// The pomodoro done is older than a whole cycle of breaks skipped after it
+	intervals := []pomodoro.Interval{{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone}}
+	for range 2 * len(pomodoro.DefaultCycle) {
+		intervals = append(intervals, pomodoro.Interval{Category: pomodoro.CategoryShortBreak, State: pomodoro.StateCancelled})
+	}
+	intervals = append(intervals, pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateRunning})
+	for _, i := range intervals {
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i, err := pomodoro.LastPomodoro(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.ID != 1 {
+		t.Errorf("Expected pomodoro 1, got %d", i.ID)
+	}
+
+	id := int64(len(intervals) + 1)
+	if err := repo.SetLabel(id, "label"); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrInvalidID, err)
+	}
+	if err := repo.SetNote(id, "note"); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q", pomodoro.ErrInvalidID, err)
+	}
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 )
@@ -10,11 +12,13 @@ import (
 type inMemoryRepo struct {
 	sync.RWMutex
 	intervals []pomodoro.Interval
+	segments  map[int64][]pomodoro.Segment
 }
 
 func NewInMemoryRepo() *inMemoryRepo {
 	return &inMemoryRepo{
 		intervals: []pomodoro.Interval{},
+		segments:  map[int64][]pomodoro.Segment{},
 	}
 }
 
@@ -35,7 +39,24 @@ func (r *inMemoryRepo) Update(i pomodoro.Interval) error {
 		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
 	}
 
-	r.intervals[i.ID-1] = i
+	// Only what the sqlite repository writes, the rest is set on creation
+	// or with SetLabel and SetNote
+	stored := &r.intervals[i.ID-1]
+	stored.StartTime, stored.ActualDuration, stored.State = i.StartTime, i.ActualDuration, i.State
+	return nil
+}
+
+func (r *inMemoryRepo) UpdateProgress(id int64, d time.Duration) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if id < 1 || id > int64(len(r.intervals)) {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+	if r.intervals[id-1].State != pomodoro.StateRunning {
+		return fmt.Errorf("%w: %d", pomodoro.ErrIntervalNotRunning, id)
+	}
+	r.intervals[id-1].ActualDuration = d
 	return nil
 }
 
@@ -44,7 +65,7 @@ func (r *inMemoryRepo) ByID(id int64) (pomodoro.Interval, error) {
 	defer r.Unlock()
 
 	i := pomodoro.Interval{}
-	if id == 0 {
+	if id < 1 || id > int64(len(r.intervals)) {
 		return i, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
 	}
 
@@ -64,21 +85,91 @@ func (r *inMemoryRepo) Last() (pomodoro.Interval, error) {
 	return r.intervals[len(r.intervals)-1], nil
 }
 
-func (r *inMemoryRepo) Breaks(n int) ([]pomodoro.Interval, error) {
+func (r *inMemoryRepo) LastPomodoro() (pomodoro.Interval, error) {
 	r.RLock()
 	defer r.RUnlock()
-	data := []pomodoro.Interval{}
 	for k := len(r.intervals) - 1; k >= 0; k-- {
-		if r.intervals[k].Category == pomodoro.CategoryPomodoro {
+		i := r.intervals[k]
+		if i.Category == pomodoro.CategoryPomodoro && (i.State == pomodoro.StateDone || i.State == pomodoro.StateCancelled) {
+			return i, nil
+		}
+	}
+	return pomodoro.Interval{}, pomodoro.ErrNoIntervals
+}
+
+func (r *inMemoryRepo) Range(start, end time.Time) ([]pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.StartTime.Before(start) || !i.StartTime.Before(end) {
 			continue
 		}
+		data = append(data, i)
+	}
+	slices.SortStableFunc(data, func(a, b pomodoro.Interval) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
 
-		data = append(data, r.intervals[k])
+	return data, nil
+}
 
-		if len(data) == n {
-			return data, nil
-		}
+func (r *inMemoryRepo) Recent(n int) ([]pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for k := len(r.intervals) - 1; k >= 0 && len(data) < n; k-- {
+		data = append(data, r.intervals[k])
 	}
 
 	return data, nil
 }
+
+func (r *inMemoryRepo) Segments(id int64) ([]pomodoro.Segment, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return slices.Clone(r.segments[id]), nil
+}
+
+func (r *inMemoryRepo) StartSegment(id int64, start time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.segments[id] = append(r.segments[id], pomodoro.Segment{Start: start})
+	return nil
+}
+
+func (r *inMemoryRepo) EndSegment(id int64, end time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+
+	segments := r.segments[id]
+	if len(segments) == 0 || !segments[len(segments)-1].End.IsZero() {
+		return fmt.Errorf("%w: %d has no running segment", pomodoro.ErrInvalidID, id)
+	}
+	segments[len(segments)-1].End = end
+	return nil
+}
+
+func (r *inMemoryRepo) SetLabel(id int64, label string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if id < 1 || id > int64(len(r.intervals)) {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+	r.intervals[id-1].Label = label
+	return nil
+}
+
+func (r *inMemoryRepo) SetNote(id int64, note string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if id < 1 || id > int64(len(r.intervals)) {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+	r.intervals[id-1].Note = note
+	return nil
+}
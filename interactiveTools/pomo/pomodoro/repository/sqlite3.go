@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"sync"
 	"time"
 
@@ -19,6 +20,68 @@ const createTableInterval string = `CREATE TABLE IF NOT EXISTS "interval" (
 	PRIMARY KEY ("id")
 	);`
 
+// createTableSegment adds the periods the intervals ran, end_time being NULL
+// while running. The intervals recorded before get a segment of their duration
+const createTableSegment string = `CREATE TABLE IF NOT EXISTS "segment" (
+	"id" INTEGER,
+	"interval_id" INTEGER NOT NULL REFERENCES "interval" ("id"),
+	"start_time" DATETIME NOT NULL,
+	"end_time" DATETIME,
+	PRIMARY KEY ("id")
+	);
+	CREATE INDEX IF NOT EXISTS "segment_interval_id" ON "segment" ("interval_id");
+	INSERT INTO segment (interval_id, start_time, end_time)
+	SELECT id, start_time, strftime('%Y-%m-%d %H:%M:%f+00:00', julianday(start_time) + actual_duration / 86400e9)
+	FROM interval WHERE actual_duration > 0;`
+
+// addIntervalLabel adds what the pomodoros are spent on and a note once over
+const addIntervalLabel string = `ALTER TABLE "interval" ADD COLUMN "label" TEXT NOT NULL DEFAULT '';
+	ALTER TABLE "interval" ADD COLUMN "note" TEXT NOT NULL DEFAULT '';`
+
+// addCycleStart marks the pomodoro a reset restarts the cycle from
+const addCycleStart string = `ALTER TABLE "interval" ADD COLUMN "cycle_start" BOOLEAN NOT NULL DEFAULT 0;`
+
+// migrations upgrade the schema one version at a time, migrations[k] moving
+// the database from version k to k+1. Only append to it: the version applied
+// is recorded in the user_version pragma of the database
+var migrations = []string{
+	createTableInterval,
+	createTableSegment,
+	addIntervalLabel,
+	addCycleStart,
+}
+
+// migrate applies the migrations the database doesn't have yet
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", version, len(migrations))
+	}
+
+	for v := version; v < len(migrations); v++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[v]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration to schema version %d: %w", v+1, err)
+		}
+		// PRAGMA doesn't accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type dbRepo struct {
 	db           *sql.DB
 	sync.RWMutex // prevent concurrent access to db
@@ -36,7 +99,7 @@ func NewSqlite3Repo(dbFile string) (*dbRepo, error) {
 		return nil, err
 	}
 
-	if _, err := db.Exec(createTableInterval); err != nil {
+	if err := migrate(db); err != nil {
 		return nil, err
 	}
 
@@ -48,13 +111,13 @@ func NewSqlite3Repo(dbFile string) (*dbRepo, error) {
 func (r *dbRepo) Create(i pomodoro.Interval) (int64, error) {
 	r.Lock()
 	defer r.Unlock()
-	insertStm, err := r.db.Prepare("INSERT INTO interval VALUES(NULL,?,?,?,?,?)")
+	insertStm, err := r.db.Prepare("INSERT INTO interval VALUES(NULL,?,?,?,?,?,?,?,?)")
 	if err != nil {
 		return 0, err
 	}
 	defer insertStm.Close()
 
-	res, err := insertStm.Exec(i.StartTime, i.PlannedDuration, i.ActualDuration, i.Category, i.State)
+	res, err := insertStm.Exec(i.StartTime, i.PlannedDuration, i.ActualDuration, i.Category, i.State, i.Label, i.Note, i.CycleStart)
 	if err != nil {
 		return 0, err
 	}
@@ -86,12 +149,30 @@ func (r *dbRepo) Update(i pomodoro.Interval) error {
 	return nil
 }
 
+func (r *dbRepo) UpdateProgress(id int64, d time.Duration) error {
+	r.Lock()
+	defer r.Unlock()
+	res, err := r.db.Exec("UPDATE interval SET actual_duration=? WHERE id=? AND state=?", d, id, pomodoro.StateRunning)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", pomodoro.ErrIntervalNotRunning, id)
+	}
+	return nil
+}
+
 func (r *dbRepo) ByID(id int64) (pomodoro.Interval, error) {
 	r.RLock()
 	defer r.RUnlock()
 	row := r.db.QueryRow("SELECT * FROM interval WHERE id=?", id)
 	i := pomodoro.Interval{}
-	err := row.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category, &i.State)
+	err := row.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category, &i.State, &i.Label, &i.Note, &i.CycleStart)
 	if err != nil {
 		return i, err
 	}
@@ -104,7 +185,7 @@ func (r *dbRepo) Last() (pomodoro.Interval, error) {
 
 	last := pomodoro.Interval{}
 	// Query the latest , we sort by id for now
-	err := r.db.QueryRow("SELECT * FROM interval ORDER BY id desc LIMIT 1").Scan(&last.ID, &last.StartTime, &last.PlannedDuration, &last.ActualDuration, &last.Category, &last.State)
+	err := r.db.QueryRow("SELECT * FROM interval ORDER BY id desc LIMIT 1").Scan(&last.ID, &last.StartTime, &last.PlannedDuration, &last.ActualDuration, &last.Category, &last.State, &last.Label, &last.Note, &last.CycleStart)
 	if err == sql.ErrNoRows {
 		return last, pomodoro.ErrNoIntervals
 	}
@@ -116,12 +197,27 @@ func (r *dbRepo) Last() (pomodoro.Interval, error) {
 	return last, nil
 }
 
-func (r *dbRepo) Breaks(n int) ([]pomodoro.Interval, error) {
+func (r *dbRepo) LastPomodoro() (pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	last := pomodoro.Interval{}
+	err := r.db.QueryRow("SELECT * FROM interval WHERE category=? AND state IN (?, ?) ORDER BY id desc LIMIT 1",
+		pomodoro.CategoryPomodoro, pomodoro.StateDone, pomodoro.StateCancelled).Scan(&last.ID, &last.StartTime, &last.PlannedDuration, &last.ActualDuration, &last.Category, &last.State, &last.Label, &last.Note, &last.CycleStart)
+	if err == sql.ErrNoRows {
+		return last, pomodoro.ErrNoIntervals
+	}
+	return last, err
+}
+
+func (r *dbRepo) Range(start, end time.Time) ([]pomodoro.Interval, error) {
 	r.RLock()
 	defer r.RUnlock()
-	stmt := `SELECT * FROM interval WHERE category LIKE '%Break' ORDER BY id DESC LIMIT ?`
+	// julianday compares the times whatever the time zone they were stored in
+	stmt := `SELECT * FROM interval WHERE julianday(start_time) >= julianday(?) AND julianday(start_time) < julianday(?)
+	ORDER BY start_time`
 
-	rows, err := r.db.Query(stmt, n)
+	rows, err := r.db.Query(stmt, start.UTC(), end.UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -131,17 +227,116 @@ func (r *dbRepo) Breaks(n int) ([]pomodoro.Interval, error) {
 
 	for rows.Next() {
 		i := pomodoro.Interval{}
-		err := rows.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category, &i.State)
+		err := rows.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category, &i.State, &i.Label, &i.Note, &i.CycleStart)
 		if err != nil {
 			return nil, err
 		}
 		data = append(data, i)
 	}
 
-	err = rows.Err()
+	return data, rows.Err()
+}
+
+func (r *dbRepo) Recent(n int) ([]pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+	rows, err := r.db.Query(`SELECT * FROM interval ORDER BY id DESC LIMIT ?`, n)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	data := []pomodoro.Interval{}
 
-	return data, nil
+	for rows.Next() {
+		i := pomodoro.Interval{}
+		err := rows.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category, &i.State, &i.Label, &i.Note, &i.CycleStart)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, i)
+	}
+
+	return data, rows.Err()
+}
+
+func (r *dbRepo) Segments(id int64) ([]pomodoro.Segment, error) {
+	r.RLock()
+	defer r.RUnlock()
+	rows, err := r.db.Query(`SELECT start_time, end_time FROM segment WHERE interval_id=? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []pomodoro.Segment{}
+
+	for rows.Next() {
+		s := pomodoro.Segment{}
+		var end sql.NullTime
+		if err := rows.Scan(&s.Start, &end); err != nil {
+			return nil, err
+		}
+		s.End = end.Time
+		data = append(data, s)
+	}
+
+	return data, rows.Err()
+}
+
+func (r *dbRepo) StartSegment(id int64, start time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+	_, err := r.db.Exec("INSERT INTO segment (interval_id, start_time) VALUES(?,?)", id, start)
+	return err
+}
+
+func (r *dbRepo) EndSegment(id int64, end time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+	res, err := r.db.Exec("UPDATE segment SET end_time=? WHERE interval_id=? AND end_time IS NULL", end, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d has no running segment", pomodoro.ErrInvalidID, id)
+	}
+	return nil
+}
+
+func (r *dbRepo) SetLabel(id int64, label string) error {
+	r.Lock()
+	defer r.Unlock()
+	res, err := r.db.Exec("UPDATE interval SET label=? WHERE id=?", label, id)
+	if err != nil {
+		return err
+	}
+	return affectedID(res, id)
+}
+
+func (r *dbRepo) SetNote(id int64, note string) error {
+	r.Lock()
+	defer r.Unlock()
+	res, err := r.db.Exec("UPDATE interval SET note=? WHERE id=?", note, id)
+	if err != nil {
+		return err
+	}
+	return affectedID(res, id)
+}
+
+// affectedID returns ErrInvalidID when the update found no interval by id
+func affectedID(res sql.Result, id int64) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+	return nil
 }
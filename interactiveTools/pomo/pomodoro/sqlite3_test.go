@@ -1,8 +1,14 @@
+//go:build !inmemory
+// +build !inmemory
+
 package pomodoro_test
 
 import (
+	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"haonguyen.tech/interactiveTools/pomo/pomodoro"
 	"haonguyen.tech/interactiveTools/pomo/pomodoro/repository"
@@ -25,3 +31,53 @@ func getRepo(t *testing.T) (pomodoro.Repository, func()) {
 		os.Remove(tf.Name())
 	}
 }
+
+func TestSqlite3Migrations(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "pomo.db")
+
+	// Database created before the schema was versioned
+	db, err := sql.Open("sqlite3", dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	legacy := []string{
+		`CREATE TABLE "interval" ("id" INTEGER, "start_time" DATETIME NOT NULL, "planned_duration" INTEGER DEFAULT 0,
+			"actual_duration" INTEGER DEFAULT 0, "category" TEXT NOT NULL, "state" INTEGER DEFAULT 1, PRIMARY KEY ("id"));`,
+		`INSERT INTO interval VALUES(NULL, '2024-03-01 09:30:00+00:00', 1500000000000, 600000000000, 'Pomodoro', 2)`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Opening twice checks the migrations aren't applied again
+	for k := 0; k < 2; k++ {
+		repo, err := repository.NewSqlite3Repo(dbFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := repo.ByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.State != pomodoro.StatePaused || i.ActualDuration != 10*time.Minute || i.Label != "" || i.CycleStart {
+			t.Errorf("Expected the legacy interval to be kept, got %v", i)
+		}
+		segments, err := repo.Segments(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 1 || segments[0].End.Sub(segments[0].Start) != 10*time.Minute {
+			t.Errorf("Expected the legacy interval to get a segment of its duration, got %v", segments)
+		}
+	}
+
+	if _, err := db.Exec("PRAGMA user_version = 1000"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.NewSqlite3Repo(dbFile); err == nil {
+		t.Error("Expected error opening a database with a newer schema")
+	}
+}
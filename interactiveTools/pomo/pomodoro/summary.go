@@ -0,0 +1,70 @@
+package pomodoro
+
+import "time"
+
+// startOfDay returns midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// LastDays returns the start of the first of the n days ending with the day
+// of now, and the end of the last one
+func LastDays(now time.Time, n int) (time.Time, time.Time) {
+	return startOfDay(now).AddDate(0, 0, 1-n), startOfDay(now).AddDate(0, 0, 1)
+}
+
+// lastPomodoros returns the pomodoros started during the n days ending with
+// the day of now, and the start of the first day
+func lastPomodoros(config *IntervalConfig, now time.Time, n int) ([]Interval, time.Time, error) {
+	first, end := LastDays(now, n)
+	intervals, err := config.repo.Range(first, end)
+	if err != nil {
+		return nil, first, err
+	}
+
+	pomodoros := []Interval{}
+	for _, i := range intervals {
+		if i.Category == CategoryPomodoro {
+			pomodoros = append(pomodoros, i)
+		}
+	}
+	return pomodoros, first, nil
+}
+
+// DailySummary returns the time focused on pomodoros each day of the n days
+// ending with the day of now, oldest first
+func DailySummary(config *IntervalConfig, now time.Time, n int) ([]time.Duration, error) {
+	pomodoros, first, err := lastPomodoros(config, now, n)
+	if err != nil {
+		return nil, err
+	}
+
+	focus := make([]time.Duration, n)
+	for _, i := range pomodoros {
+		// Counting days by date keeps the days changing of length with DST right
+		day := 0
+		for d := first.AddDate(0, 0, 1); !i.StartTime.Before(d); d = d.AddDate(0, 0, 1) {
+			day++
+		}
+		focus[day] += i.ActualDuration
+	}
+	return focus, nil
+}
+
+// HourlySummary returns the number of pomodoros done over the n days ending
+// with the day of now, by the hour of day they started at
+func HourlySummary(config *IntervalConfig, now time.Time, n int) ([24]int, error) {
+	var count [24]int
+	pomodoros, _, err := lastPomodoros(config, now, n)
+	if err != nil {
+		return count, err
+	}
+
+	for _, i := range pomodoros {
+		if i.State == StateDone {
+			count[i.StartTime.In(now.Location()).Hour()]++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package pomodoro_test
+
+import (
+	"testing"
+	"time"
+
+	"haonguyen.tech/interactiveTools/pomo/pomodoro"
+)
+
+func TestSummary(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	now := time.Date(2024, 3, 7, 15, 0, 0, 0, loc)
+	intervals := []pomodoro.Interval{
+		// Too old
+		{StartTime: now.AddDate(0, 0, -7), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		// First day, stored in UTC
+		{StartTime: time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		{StartTime: time.Date(2024, 3, 1, 8, 30, 0, 0, loc), ActualDuration: 5 * time.Minute, Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+		{StartTime: time.Date(2024, 3, 5, 8, 40, 0, 0, loc), ActualDuration: 10 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateCancelled},
+		{StartTime: time.Date(2024, 3, 7, 14, 0, 0, 0, loc), ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		{StartTime: time.Date(2024, 3, 7, 14, 30, 0, 0, loc), ActualDuration: 20 * time.Minute, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		// Not started yet
+		{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateNotStarted},
+	}
+	for _, i := range intervals {
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	daily, err := pomodoro.DailySummary(config, now, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expDaily := []time.Duration{25 * time.Minute, 0, 0, 0, 10 * time.Minute, 0, 45 * time.Minute}
+	if len(daily) != len(expDaily) {
+		t.Fatalf("Expected %d days, got %d", len(expDaily), len(daily))
+	}
+	for k := range expDaily {
+		if daily[k] != expDaily[k] {
+			t.Errorf("Expected %s focused on day %d, got %s", expDaily[k], k, daily[k])
+		}
+	}
+
+	hourly, err := pomodoro.HourlySummary(config, now, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expHourly := [24]int{8: 1, 14: 2}
+	if hourly != expHourly {
+		t.Errorf("Expected %v, got %v", expHourly, hourly)
+	}
+}
+
+func TestRange(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// Created out of order, like an interval started again after a newer one
+	now := time.Now()
+	for _, d := range []time.Duration{2 * time.Hour, 3 * time.Hour, time.Hour} {
+		if _, err := repo.Create(pomodoro.Interval{StartTime: now.Add(-d), Category: pomodoro.CategoryPomodoro}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := repo.Range(now.Add(-4*time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expIDs := []int64{2, 1, 3}
+	if len(data) != len(expIDs) {
+		t.Fatalf("Expected %d intervals, got %d", len(expIDs), len(data))
+	}
+	for k, id := range expIDs {
+		if data[k].ID != id {
+			t.Errorf("Expected interval %d at %d, got %d", id, k, data[k].ID)
+		}
+	}
+}
+
+func TestLastDays(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	now := time.Date(2024, 3, 7, 15, 0, 0, 0, loc)
+
+	start, end := pomodoro.LastDays(now, 7)
+	if exp := time.Date(2024, 3, 1, 0, 0, 0, 0, loc); !start.Equal(exp) {
+		t.Errorf("Expected start %s, got %s", exp, start)
+	}
+	if exp := time.Date(2024, 3, 8, 0, 0, 0, 0, loc); !end.Equal(exp) {
+		t.Errorf("Expected end %s, got %s", exp, end)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"todo"
 )
@@ -32,6 +33,8 @@ func main() {
 	del := flag.Int("delete", 0, "Item to be deleted")
 	verbose := flag.Bool("verbose", false, "Enable verbose for more information in the output")
 	hideComplete := flag.Bool("hide-complete", false, "Hide completed Item")
+	due := flag.Bool("due", false, "Show the overdue tasks and the ones due today or this week")
+	dueDate := flag.String("due-date", "", "Due date of the task to add, YYYY-MM-DD or RFC 3339")
 	flag.Parse()
 
 	l := &todo.List{VerboseMode: *verbose, HideComplete: *hideComplete}
@@ -55,6 +58,17 @@ func main() {
 			os.Exit(1)
 		}
 		l.Add(task)
+		if *dueDate != "" {
+			d, err := todo.ParseDue(*dueDate, time.Local)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if err := l.SetDue(len(l.Items), d); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
 		// Save to the list
 		if err := l.Save(todoFileName); err != nil {
 			fmt.Fprintln(os.Stderr, err)
@@ -64,6 +78,10 @@ func main() {
 		// List current to do items
 		fmt.Print(l)
 
+	case *due:
+		// Report the pending tasks by due date
+		fmt.Print(l.Due(time.Now()))
+
 	case *complete > 0:
 		// Complete the given item
 		if err := l.Complete(*complete); err != nil {
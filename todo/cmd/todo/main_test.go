@@ -7,7 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -141,4 +143,30 @@ func TestTodoCLI(t *testing.T) {
 			t.Errorf("Expected %q, got %s instead \n", expected, out)
 		}
 	})
+
+	t.Run("DueReport", func(t *testing.T) {
+		yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		cmd := exec.Command(cmdPath, "-add", "-due-date", yesterday, "late task")
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd = exec.Command(cmdPath, "-due")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "Overdue:\n  2: late task (due "
+		if !strings.HasPrefix(string(out), expected) {
+			t.Errorf("Expected prefix %q, got %s instead \n", expected, out)
+		}
+	})
+
+	t.Run("InvalidDueDate", func(t *testing.T) {
+		cmd := exec.Command(cmdPath, "-add", "-due-date", "someday", "task")
+		if err := cmd.Run(); err == nil {
+			t.Error("Expected error for an invalid due date")
+		}
+	})
 }
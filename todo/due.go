@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dueDateLayout is the layout of due dates given without a time
+const dueDateLayout = "2006-01-02"
+
+// DueItem is a pending item with a due date and its position in the list
+type DueItem struct {
+	ID int
+	Item
+}
+
+// DueReport groups the pending items with a due date
+type DueReport struct {
+	Overdue []DueItem `json:"overdue"`
+	Today   []DueItem `json:"today"`
+	// Week holds the items due in the 7 days after today
+	Week []DueItem `json:"week"`
+}
+
+// ParseDue parses a due date given as a date, meaning the end of that day in
+// loc, or as an RFC 3339 time
+func ParseDue(s string, loc *time.Location) (time.Time, error) {
+	if d, err := time.ParseInLocation(dueDateLayout, s, loc); err == nil {
+		return d.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	d, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid due date %q, use YYYY-MM-DD or RFC 3339", s)
+	}
+	return d, nil
+}
+
+// SetDue sets the due date of the item i, a zero date removes it
+func (l *List) SetDue(i int, due time.Time) error {
+	if i <= 0 || i > len(l.Items) {
+		return fmt.Errorf("item %d does not exist", i)
+	}
+
+	l.Items[i-1].Due = due
+	return nil
+}
+
+// Due returns the pending items overdue or due within a week of now,
+// the days starting at midnight in the location of now
+func (l *List) Due(now time.Time) DueReport {
+	r := DueReport{Overdue: []DueItem{}, Today: []DueItem{}, Week: []DueItem{}}
+
+	y, m, d := now.Date()
+	tomorrow := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+	weekEnd := tomorrow.AddDate(0, 0, 7)
+
+	for k, item := range l.Items {
+		if item.Done || item.Due.IsZero() {
+			continue
+		}
+
+		i := DueItem{ID: k + 1, Item: item}
+		switch {
+		case item.Due.Before(now):
+			r.Overdue = append(r.Overdue, i)
+		case item.Due.Before(tomorrow):
+			r.Today = append(r.Today, i)
+		case item.Due.Before(weekEnd):
+			r.Week = append(r.Week, i)
+		}
+	}
+	return r
+}
+
+// Implements the fmt.Stringer interface
+func (r DueReport) String() string {
+	var b strings.Builder
+	section := func(title string, items []DueItem, layout string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		for _, i := range items {
+			fmt.Fprintf(&b, "  %d: %s (due %s)\n", i.ID, i.Task, i.Due.Format(layout))
+		}
+	}
+	section("Overdue", r.Overdue, "Jan 02 15:04")
+	section("Due today", r.Today, "15:04")
+	section("Due this week", r.Week, "Mon Jan 02 15:04")
+
+	if b.Len() == 0 {
+		return "Nothing due this week.\n"
+	}
+	return b.String()
+}
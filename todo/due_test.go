@@ -0,0 +1,85 @@
+package todo_test
+
+import (
+	"testing"
+	"time"
+	"todo"
+)
+
+func TestParseDue(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		exp    time.Time
+		expErr bool
+	}{
+		{name: "Date", input: "2024-03-01", exp: time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)},
+		{name: "RFC3339", input: "2024-03-01T09:30:00Z", exp: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+		{name: "Invalid", input: "tomorrow", expErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := todo.ParseDue(tc.input, time.UTC)
+			if tc.expErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !d.Equal(tc.exp) {
+				t.Errorf("Expected %s, got %s", tc.exp, d)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := todo.List{}
+	tasks := []struct {
+		task string
+		due  time.Time
+		done bool
+	}{
+		{"No due date", time.Time{}, false},
+		{"Yesterday", now.AddDate(0, 0, -1), false},
+		{"This morning", now.Add(-time.Hour), false},
+		{"Tonight", now.Add(8 * time.Hour), false},
+		{"Next week", now.AddDate(0, 0, 6), false},
+		{"Next month", now.AddDate(0, 1, 0), false},
+		{"Done late", now.AddDate(0, 0, -2), true},
+	}
+	for k, task := range tasks {
+		l.Add(task.task)
+		if err := l.SetDue(k+1, task.due); err != nil {
+			t.Fatal(err)
+		}
+		if task.done {
+			_ = l.Complete(k + 1)
+		}
+	}
+
+	r := l.Due(now)
+	exp := `Overdue:
+  2: Yesterday (due Feb 29 12:00)
+  3: This morning (due Mar 01 11:00)
+Due today:
+  4: Tonight (due 20:00)
+Due this week:
+  5: Next week (due Thu Mar 07 12:00)
+`
+	if r.String() != exp {
+		t.Errorf("Expected report %q, got %q", exp, r.String())
+	}
+
+	if s := (&todo.List{}).Due(now).String(); s != "Nothing due this week.\n" {
+		t.Errorf("Expected empty report, got %q", s)
+	}
+	if err := l.SetDue(10, now); err == nil {
+		t.Error("Expected error setting the due date of a missing item")
+	}
+}
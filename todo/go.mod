@@ -1,3 +1,3 @@
 module todo
 
-go 1.22.1
+go 1.24
@@ -13,6 +13,8 @@ type Item struct {
 	CreatedAt   time.Time
 	Task        string
 	Done        bool
+	// Due is the optional deadline of the item, zero when none
+	Due time.Time `json:",omitzero"`
 }
 
 // List represents a list of ToDo items and Verbose mode